@@ -0,0 +1,154 @@
+// Package bernoulli implements a Bernoulli naive bayes classifier, which
+// models every word of the vocabulary as present or absent in a document
+// rather than counting its occurrences. It suits short documents such as
+// titles, where the absence of a category's characteristic words is
+// evidence against that category.
+package bernoulli
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/carautenbach/classifier"
+)
+
+var _ classifier.Classifier = (*Classifier)(nil)
+
+// Classifier implements a Bernoulli naive bayes classifier
+type Classifier struct {
+	// Feat2cat holds, for every word, the number of documents of each
+	// category that contain it at least once
+	Feat2cat  map[string]map[string]float64
+	CatCount  map[string]float64
+	Tokenizer classifier.Tokenizer
+	// Alpha is the additive smoothing strength applied to every word
+	// probability as (documents containing the word + Alpha) / (documents +
+	// 2 * Alpha); 0 disables smoothing
+	Alpha float64
+
+	mu sync.RWMutex
+}
+
+// New initializes a new Bernoulli Classifier using the standard tokenizer and
+// Laplace smoothing (Alpha 1)
+func New() *Classifier {
+	return &Classifier{
+		Feat2cat:  make(map[string]map[string]float64),
+		CatCount:  make(map[string]float64),
+		Tokenizer: classifier.NewTokenizer(),
+		Alpha:     1,
+	}
+}
+
+// Train provides supervisory training to the classifier. Every word is
+// counted once per document, however often it occurs.
+func (c *Classifier) Train(r io.Reader, category string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for word := range c.words(r) {
+		if c.Feat2cat[word] == nil {
+			c.Feat2cat[word] = make(map[string]float64)
+		}
+		c.Feat2cat[word][category]++
+	}
+	c.CatCount[category]++
+	return nil
+}
+
+// TrainString provides supervisory training to the classifier
+func (c *Classifier) TrainString(title string, category string) error {
+	return c.Train(bytes.NewBufferString(title), category)
+}
+
+// Classify returns the top category for the document read from r, or an
+// empty string when the model has not been trained
+func (c *Classifier) Classify(r io.Reader) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, topCategory := c.probabilities(c.words(r))
+	return topCategory, nil
+}
+
+// ClassifyString returns the top category for the provided string, or an
+// empty string when the model has not been trained
+func (c *Classifier) ClassifyString(s string) (string, error) {
+	return c.Classify(bytes.NewBufferString(s))
+}
+
+// Probabilities returns the posterior probability of every category for the
+// provided string, normalized to sum to 1, along with the top category. Every
+// word of the vocabulary contributes: P(word|category) when the string
+// contains it and 1 - P(word|category) when it does not. Words unknown to the
+// model are ignored.
+func (c *Classifier) Probabilities(s string) (map[string]float64, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.probabilities(c.words(bytes.NewBufferString(s)))
+}
+
+// words returns the distinct tokens of the document
+func (c *Classifier) words(r io.Reader) map[string]bool {
+	words := make(map[string]bool)
+	for token := range c.Tokenizer.Tokenize(r) {
+		words[token] = true
+	}
+	return words
+}
+
+// probabilities scores every category in log space and normalizes the scores
+// with the log-sum-exp trick
+func (c *Classifier) probabilities(words map[string]bool) (map[string]float64, string) {
+	total := 0.0
+	for _, count := range c.CatCount {
+		total += count
+	}
+
+	categories := make([]string, 0, len(c.CatCount))
+	for category := range c.CatCount {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	scores := make(map[string]float64, len(categories))
+	for _, category := range categories {
+		score := math.Log(c.CatCount[category] / total)
+		for word, counts := range c.Feat2cat {
+			p := (counts[category] + c.Alpha) / (c.CatCount[category] + 2*c.Alpha)
+			if words[word] {
+				score += math.Log(p)
+			} else {
+				score += math.Log(1 - p)
+			}
+		}
+		scores[category] = score
+	}
+
+	topCategory := ""
+	top := math.Inf(-1)
+	for _, category := range categories {
+		if scores[category] > top {
+			topCategory, top = category, scores[category]
+		}
+	}
+
+	probabilities := make(map[string]float64, len(categories))
+	if math.IsInf(top, -1) {
+		return probabilities, ""
+	}
+	sum := 0.0
+	for _, category := range categories {
+		sum += math.Exp(scores[category] - top)
+	}
+	for _, category := range categories {
+		if p := math.Exp(scores[category]-top) / sum; p > 0 {
+			probabilities[category] = p
+		}
+	}
+	return probabilities, topCategory
+}
@@ -0,0 +1,82 @@
+package bernoulli
+
+import (
+	"math"
+	"testing"
+
+	"github.com/carautenbach/classifier/naive"
+)
+
+var examples = []naive.Example{
+	{Text: "German Shepherd", Category: "Dog"},
+	{Text: "Pointer", Category: "Dog"},
+	{Text: "Black kitty", Category: "Cat"},
+	{Text: "White kitten", Category: "Cat"},
+	{Text: "White kitty", Category: "Cat"},
+	{Text: "Guppy kitty", Category: "Fish"},
+	{Text: "Guppy king", Category: "Fish"},
+}
+
+func TestClassifier(t *testing.T) {
+	bernoulli, multinomial := New(), naive.New()
+	for _, example := range examples {
+		bernoulli.TrainString(example.Text, example.Category)
+		multinomial.TrainString(example.Text, example.Category)
+	}
+
+	tests := []struct {
+		Name        string
+		Input       string
+		Bernoulli   string
+		Multinomial string
+	}{
+		{"Agreement", "White kitty", "Cat", "Cat"},
+		// repeating "kitty" adds evidence for Cat to the multinomial model only
+		{"Repetition", "kitty kitty kitty guppy", "Fish", "Cat"},
+		// the absence of "kitty", present in most Cat documents, counts
+		// against Cat in the Bernoulli model only
+		{"Absence", "White guppy", "Fish", "Cat"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			probabilities, topResult := bernoulli.Probabilities(test.Input)
+			if topResult != test.Bernoulli {
+				t.Errorf("Expected %s; actual: %s", test.Bernoulli, topResult)
+			}
+			if label, _ := bernoulli.ClassifyString(test.Input); label != topResult {
+				t.Errorf("Expected %s; actual: %s", topResult, label)
+			}
+			sum := 0.0
+			for _, p := range probabilities {
+				sum += p
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("Expected the probabilities to sum to 1; actual: %v", sum)
+			}
+
+			if _, topResult := multinomial.Probabilities(test.Input); topResult != test.Multinomial {
+				t.Errorf("Expected %s; actual: %s", test.Multinomial, topResult)
+			}
+		})
+	}
+}
+
+func TestTrainCountsDocuments(t *testing.T) {
+	c := New()
+	c.TrainString("kitty kitty kitty", "Cat")
+	c.TrainString("white kitty", "Cat")
+
+	if count := c.Feat2cat["kitty"]["Cat"]; count != 2 {
+		t.Errorf("Expected %v; actual: %v", 2, count)
+	}
+	if count := c.CatCount["Cat"]; count != 2 {
+		t.Errorf("Expected %v; actual: %v", 2, count)
+	}
+}
+
+func TestUntrained(t *testing.T) {
+	if label, err := New().ClassifyString("kitty"); label != "" || err != nil {
+		t.Errorf("Expected no category; actual: %q, %v", label, err)
+	}
+}
@@ -2,4 +2,6 @@ module github.com/carautenbach/classifier
 
 go 1.17
 
-require github.com/kljensen/snowball v0.6.0 // indirect
+require golang.org/x/text v0.13.0
+
+require github.com/kljensen/snowball v0.6.0
@@ -0,0 +1,235 @@
+package naive
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Example is a single labeled training document
+type Example struct {
+	Text     string
+	Category string
+}
+
+// TrainParallel trains every example, tokenizing them concurrently across the
+// given number of workers (runtime.NumCPU when workers < 1). Tokenized
+// documents are funneled back to the calling goroutine, which applies the
+// count updates one document at a time under the write lock, so the result is
+// identical to training the examples serially.
+func (c *Classifier) TrainParallel(examples []Example, workers int) error {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	type document struct {
+		example  Example
+		features []feature
+	}
+
+	jobs := make(chan Example)
+	documents := make(chan document, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for example := range jobs {
+				features := c.featuresOf(c.Tokenizer.Tokenize(AsReader(example.Text)))
+				documents <- document{example: example, features: features}
+			}
+		}()
+	}
+
+	go func() {
+		for _, example := range examples {
+			jobs <- example
+		}
+		close(jobs)
+		wg.Wait()
+		close(documents)
+	}()
+
+	for doc := range documents {
+		c.mu.Lock()
+		for _, feature := range doc.features {
+			c.addWord(feature.word, doc.example.Category)
+		}
+		c.addDocument(doc.features, 1)
+		c.unshare()
+		c.CatCount[doc.example.Category]++
+		c.warm = nil
+		if c.retain {
+			c.examples = append(c.examples, doc.example)
+		}
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// BatchError reports the examples of a batch that could not be trained
+type BatchError struct {
+	// Failed maps the index of every rejected example to the reason
+	Failed map[int]error
+}
+
+func (e *BatchError) Error() string {
+	indices := make([]int, 0, len(e.Failed))
+	for i := range e.Failed {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	reasons := make([]string, len(indices))
+	for n, i := range indices {
+		reasons[n] = fmt.Sprintf("example %d: %v", i, e.Failed[i])
+	}
+	return fmt.Sprintf("%d examples not trained: %s", len(indices), strings.Join(reasons, "; "))
+}
+
+// TrainBatch trains every example, tokenizing them up front and then applying
+// all count updates under a single acquisition of the write lock. Examples
+// with blank text or an empty category are skipped and reported in a
+// *BatchError once the rest of the batch has been trained.
+func (c *Classifier) TrainBatch(examples []Example) error {
+	failed := make(map[int]error)
+	features := make([][]feature, len(examples))
+	for i, example := range examples {
+		switch {
+		case strings.TrimSpace(example.Text) == "":
+			failed[i] = errors.New("empty text")
+		case example.Category == "":
+			failed[i] = errors.New("empty category")
+		default:
+			features[i] = c.featuresOf(c.Tokenizer.Tokenize(AsReader(example.Text)))
+		}
+	}
+
+	c.mu.Lock()
+	for i, example := range examples {
+		if failed[i] != nil {
+			continue
+		}
+		for _, feature := range features[i] {
+			c.addWord(feature.word, example.Category)
+		}
+		c.addDocument(features[i], 1)
+		c.unshare()
+		c.CatCount[example.Category]++
+		if c.retain {
+			c.examples = append(c.examples, example)
+		}
+	}
+	c.warm = nil
+	c.mu.Unlock()
+
+	if len(failed) > 0 {
+		return &BatchError{Failed: failed}
+	}
+	return nil
+}
+
+// Merge adds the counts of other to the model, so merging classifiers trained
+// on shards of a corpus gives the same counts as training one classifier on
+// the whole corpus. It fails with ErrTokenizerMismatch when the classifiers
+// tokenize or hash features differently. Merged documents carry no
+// timestamps, so they count at full weight under WithHalfLife.
+func (c *Classifier) Merge(other *Classifier) error {
+	// copying the counts before taking the write lock avoids deadlocking
+	// when two classifiers are merged into each other concurrently
+	snapshot, err := other.Snapshot()
+	if err != nil {
+		return err
+	}
+	if current := tokenizerSignature(c.Tokenizer); snapshot.model.Signature != current {
+		return fmt.Errorf("%w: merging %s into %s", ErrTokenizerMismatch, snapshot.model.Signature, current)
+	}
+	if (c.salt == nil) != (other.salt == nil) || c.salt != nil && *c.salt != *other.salt {
+		return fmt.Errorf("%w: feature hashing salts differ", ErrTokenizerMismatch)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for word, counts := range snapshot.model.Feat2cat {
+		for category, count := range counts {
+			c.addWordCount(word, category, count)
+		}
+	}
+	c.unshare()
+	if c.sketch == nil {
+		for word, n := range snapshot.model.DocFreq {
+			c.docFreq[word] += n
+		}
+	}
+	for category, count := range snapshot.model.CatCount {
+		c.CatCount[category] += count
+	}
+	c.warm = nil
+	return nil
+}
+
+// SuspiciousExamples returns the retained training examples that the trained
+// model assigns to a different category while giving their own label a
+// normalized probability of at most maxConfidenceForOwnLabel. These are
+// likely mislabeled. It requires WithExampleRetention and returns nil
+// otherwise.
+func (c *Classifier) SuspiciousExamples(maxConfidenceForOwnLabel float64) []Example {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var suspicious []Example
+	for _, example := range c.examples {
+		posteriors, topCategory := c.posteriors(c.features(example.Text))
+		if topCategory == "" || topCategory == example.Category {
+			continue
+		}
+		if posteriors[example.Category] <= maxConfidenceForOwnLabel {
+			suspicious = append(suspicious, example)
+		}
+	}
+	return suspicious
+}
+
+// BalanceBySampling undersamples over-represented categories, keeping a
+// random selection of at most maxPerCategory examples of each category. The
+// selection is fully determined by the seed and the kept examples retain
+// their original order.
+func BalanceBySampling(examples []Example, maxPerCategory int, seed int64) []Example {
+	indices := make(map[string][]int)
+	for i, example := range examples {
+		indices[example.Category] = append(indices[example.Category], i)
+	}
+
+	categories := make([]string, 0, len(indices))
+	for category := range indices {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	random := rand.New(rand.NewSource(seed))
+	keep := make([]bool, len(examples))
+	for _, category := range categories {
+		members := indices[category]
+		for n, i := range random.Perm(len(members)) {
+			if n >= maxPerCategory {
+				break
+			}
+			keep[members[i]] = true
+		}
+	}
+
+	var balanced []Example
+	for i, example := range examples {
+		if keep[i] {
+			balanced = append(balanced, example)
+		}
+	}
+	return balanced
+}
@@ -0,0 +1,170 @@
+package naive
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTrainParallel(t *testing.T) {
+	var examples []Example
+	for i := 0; i < 200; i++ {
+		examples = append(examples,
+			Example{fmt.Sprintf("White kitty number %d", i%17), "Cat"},
+			Example{fmt.Sprintf("German shepherd number %d", i%13), "Dog"},
+		)
+	}
+
+	serial := New()
+	for _, example := range examples {
+		serial.TrainString(example.Text, example.Category)
+	}
+
+	for _, workers := range []int{0, 1, 4} {
+		parallel := New()
+		if err := parallel.TrainParallel(examples, workers); err != nil {
+			t.Fatalf("unable to train: %v", err)
+		}
+		if !reflect.DeepEqual(serial.Feat2cat, parallel.Feat2cat) {
+			t.Errorf("%d workers: word counts differ from serial training", workers)
+		}
+		if !reflect.DeepEqual(serial.CatCount, parallel.CatCount) {
+			t.Errorf("%d workers: expected %v; actual: %v", workers, serial.CatCount, parallel.CatCount)
+		}
+	}
+}
+
+func TestTrainBatch(t *testing.T) {
+	examples := []Example{
+		{"White kitty", "Cat"},
+		{"", "Cat"},
+		{"German shepherd", "Dog"},
+		{"Black kitty", ""},
+		{"Black kitty", "Cat"},
+	}
+
+	serial := New()
+	for _, example := range []Example{examples[0], examples[2], examples[4]} {
+		serial.TrainString(example.Text, example.Category)
+	}
+
+	batched := New()
+	err := batched.TrainBatch(examples)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a batch error; actual: %v", err)
+	}
+	if len(batchErr.Failed) != 2 || batchErr.Failed[1] == nil || batchErr.Failed[3] == nil {
+		t.Errorf("Expected examples 1 and 3 to fail; actual: %v", err)
+	}
+	if !reflect.DeepEqual(serial.Feat2cat, batched.Feat2cat) {
+		t.Errorf("Expected %v; actual: %v", serial.Feat2cat, batched.Feat2cat)
+	}
+	if !reflect.DeepEqual(serial.CatCount, batched.CatCount) {
+		t.Errorf("Expected %v; actual: %v", serial.CatCount, batched.CatCount)
+	}
+
+	if err := New().TrainBatch(examples[:1]); err != nil {
+		t.Errorf("Expected no error; actual: %v", err)
+	}
+}
+
+func BenchmarkTrainBatch(b *testing.B) {
+	examples := make([]Example, 1000)
+	for i := range examples {
+		examples[i] = Example{fmt.Sprintf("shared words for everyone plus unique%d", i), fmt.Sprintf("Category%d", i%10)}
+	}
+
+	b.Run("TrainString", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			classifier := New()
+			for _, example := range examples {
+				classifier.TrainString(example.Text, example.Category)
+			}
+		}
+	})
+	b.Run("TrainBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			New().TrainBatch(examples)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	var examples []Example
+	for i := 0; i < 50; i++ {
+		examples = append(examples,
+			Example{fmt.Sprintf("White kitty number %d", i%7), "Cat"},
+			Example{fmt.Sprintf("German shepherd number %d", i%5), "Dog"},
+		)
+	}
+
+	whole, first, second := New(), New(), New()
+	for i, example := range examples {
+		whole.TrainString(example.Text, example.Category)
+		if i < len(examples)/2 {
+			first.TrainString(example.Text, example.Category)
+		} else {
+			second.TrainString(example.Text, example.Category)
+		}
+	}
+
+	if err := first.Merge(second); err != nil {
+		t.Fatalf("unable to merge: %v", err)
+	}
+	if !reflect.DeepEqual(whole.Feat2cat, first.Feat2cat) {
+		t.Errorf("Expected %v; actual: %v", whole.Feat2cat, first.Feat2cat)
+	}
+	if !reflect.DeepEqual(whole.CatCount, first.CatCount) {
+		t.Errorf("Expected %v; actual: %v", whole.CatCount, first.CatCount)
+	}
+
+	if err := first.Merge(New(WithFeatureHashingSalt("pepper"))); !errors.Is(err, ErrTokenizerMismatch) {
+		t.Errorf("Expected %v; actual: %v", ErrTokenizerMismatch, err)
+	}
+}
+
+func TestSuspiciousExamples(t *testing.T) {
+	classifier := New(WithExampleRetention(), WithProbabilityFloor(1e-3))
+
+	for i := 0; i < 5; i++ {
+		classifier.TrainString("White kitty", "Cat")
+		classifier.TrainString("German shepherd", "Dog")
+	}
+	mislabeled := Example{"White kitty", "Dog"}
+	classifier.TrainString(mislabeled.Text, mislabeled.Category)
+
+	suspicious := classifier.SuspiciousExamples(0.3)
+	if len(suspicious) != 1 || suspicious[0] != mislabeled {
+		t.Errorf("Expected %v; actual: %v", []Example{mislabeled}, suspicious)
+	}
+
+	if suspicious := New().SuspiciousExamples(0.3); suspicious != nil {
+		t.Errorf("Expected no examples without retention; actual: %v", suspicious)
+	}
+}
+
+func TestBalanceBySampling(t *testing.T) {
+	var examples []Example
+	for i := 0; i < 20; i++ {
+		examples = append(examples, Example{fmt.Sprintf("kitty %d", i), "Cat"})
+	}
+	for i := 0; i < 3; i++ {
+		examples = append(examples, Example{fmt.Sprintf("puppy %d", i), "Dog"})
+	}
+
+	balanced := BalanceBySampling(examples, 5, 42)
+
+	counts := make(map[string]int)
+	for _, example := range balanced {
+		counts[example.Category]++
+	}
+	if expected := map[string]int{"Cat": 5, "Dog": 3}; !reflect.DeepEqual(expected, counts) {
+		t.Errorf("Expected %v; actual: %v", expected, counts)
+	}
+
+	if again := BalanceBySampling(examples, 5, 42); !reflect.DeepEqual(balanced, again) {
+		t.Errorf("Expected %v; actual: %v", balanced, again)
+	}
+}
@@ -0,0 +1,123 @@
+package naive
+
+import (
+	"math"
+	"sort"
+)
+
+// classifyStream scores the tokens one at a time, keeping a running log-score
+// per category, and stops as soon as one category's normalized posterior
+// exceeds the early stop confidence. Tokens unknown to the model carry no
+// evidence and are skipped. It returns the normalized posteriors at the point
+// it stopped, the top category and the number of tokens scored. The
+// remaining tokens are drained in the background. Callers must hold the read
+// lock.
+func (c *Classifier) classifyStream(tokens chan string) (posteriors map[string]float64, label string, scored int) {
+	totalCount := c.totalCount()
+	categories := c.getAllCategories()
+	sort.Strings(categories)
+
+	scores := make(map[string]float64, len(categories))
+	for _, category := range categories {
+		if c.prior == noPrior {
+			scores[category] = 0
+		} else {
+			scores[category] = math.Log(c.probabilityOfCategory(category, totalCount))
+		}
+	}
+
+	seen := make(map[string]bool)
+	position := 0
+	for token := range tokens {
+		token = c.hashWord(token)
+		weight := 1.0
+		if c.decay != 0 && c.decay != 1 {
+			weight = math.Pow(c.decay, float64(position))
+		}
+		position++
+
+		if c.unique {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+		}
+		if c.wordCount(token) == 0 {
+			continue
+		}
+		if c.tfidf {
+			weight *= c.idf(token)
+		}
+
+		for _, category := range categories {
+			if c.catTokens[category] == 0 {
+				scores[category] = math.Inf(-1)
+				continue
+			}
+			ratio := c.probabilityOfWordInCategory(token, category) / c.probabilityOfWordInTotalWords(token, totalCount)
+			scores[category] += weight * math.Log(ratio)
+		}
+		scored++
+
+		posteriors, label = softmaxScores(categories, scores)
+		if posteriors[label] > c.earlyStop {
+			go func() {
+				for range tokens {
+				}
+			}()
+			return posteriors, label, scored
+		}
+	}
+
+	posteriors, label = softmaxScores(categories, scores)
+	return posteriors, label, scored
+}
+
+// softmax normalizes log-scores into posteriors that sum to 1 using the
+// log-sum-exp trick
+func softmax(scores map[string]float64) map[string]float64 {
+	categories := make([]string, 0, len(scores))
+	for category := range scores {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	posteriors, _ := softmaxScores(categories, scores)
+	return posteriors
+}
+
+// softmaxScores normalizes log-scores into posteriors using the log-sum-exp
+// trick and returns them along with the top category, the first of the
+// sorted categories on ties. Categories scoring -Inf or NaN are left out.
+func softmaxScores(categories []string, scores map[string]float64) (map[string]float64, string) {
+	best := ""
+	top := math.Inf(-1)
+	for _, category := range categories {
+		if score := scores[category]; score > top {
+			best, top = category, score
+		}
+	}
+
+	posteriors := make(map[string]float64)
+	if best == "" || math.IsInf(top, 1) {
+		return posteriors, best
+	}
+
+	sum := 0.0
+	for _, category := range categories {
+		if score := scores[category]; score > math.Inf(-1) {
+			sum += math.Exp(score - top)
+		}
+	}
+	for _, category := range categories {
+		if score := scores[category]; score > math.Inf(-1) {
+			posteriors[category] = math.Exp(score-top) / sum
+		}
+	}
+	return posteriors, best
+}
+
+// streaming reports whether classification takes the early stopping path
+func (c *Classifier) streaming() bool {
+	return c.earlyStop > 0 && c.ngrams == nil && !c.complement
+}
@@ -0,0 +1,40 @@
+package naive
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithEarlyStopConfidence(t *testing.T) {
+	c := New(WithProbabilityFloor(0.01), WithEarlyStopConfidence(0.99))
+
+	c.TrainString("Fluffy kitty meows", "Cat")
+	c.TrainString("White kitty purrs", "Cat")
+	c.TrainString("German shepherd barks", "Dog")
+	c.TrainString("White pointer wags", "Dog")
+
+	input := strings.Repeat("kitty meows purrs ", 100)
+
+	c.mu.RLock()
+	posteriors, label, scored := c.classifyStream(c.Tokenizer.Tokenize(AsReader(input)))
+	c.mu.RUnlock()
+
+	if label != "Cat" {
+		t.Errorf("Expected Cat; actual: %s", label)
+	}
+	if scored == 0 || scored >= 300 {
+		t.Errorf("Expected to stop early; actual: %d of 300 tokens scored", scored)
+	}
+	if posteriors["Cat"] <= 0.99 {
+		t.Errorf("Expected a confidence above 0.99; actual: %f", posteriors["Cat"])
+	}
+
+	for _, classify := range []func(string) (string, error){c.ClassifyString, c.Predict} {
+		if actual, _ := classify(input); actual != "Cat" {
+			t.Errorf("Expected Cat; actual: %s", actual)
+		}
+	}
+	if actual, _ := c.Predict("German shepherd"); actual != "Dog" {
+		t.Errorf("Expected Dog; actual: %s", actual)
+	}
+}
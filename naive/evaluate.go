@@ -0,0 +1,329 @@
+package naive
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Metrics holds the precision, recall and F1 score of a single category
+type Metrics struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+// Report summarizes the observations recorded by a StreamEvaluator
+type Report struct {
+	Observations int
+	Accuracy     float64
+	Categories   map[string]Metrics
+}
+
+// StreamEvaluator keeps running per-category tallies of predicted versus
+// actual labels, e.g. while performing test-then-train evaluation over a
+// stream of examples. It is independent of any classifier and is not safe
+// for concurrent use.
+type StreamEvaluator struct {
+	observations   int
+	correct        int
+	truePositives  map[string]int
+	falsePositives map[string]int
+	falseNegatives map[string]int
+}
+
+// NewStreamEvaluator initializes an empty StreamEvaluator
+func NewStreamEvaluator() *StreamEvaluator {
+	return &StreamEvaluator{
+		truePositives:  make(map[string]int),
+		falsePositives: make(map[string]int),
+		falseNegatives: make(map[string]int),
+	}
+}
+
+// Observe records a single prediction against its actual label
+func (e *StreamEvaluator) Observe(predicted, actual string) {
+	e.observations++
+	if predicted == actual {
+		e.correct++
+		e.truePositives[actual]++
+		return
+	}
+	e.falsePositives[predicted]++
+	e.falseNegatives[actual]++
+}
+
+// Report returns the accuracy and per-category metrics over all observations
+// recorded so far
+func (e *StreamEvaluator) Report() Report {
+	report := Report{
+		Observations: e.observations,
+		Categories:   make(map[string]Metrics),
+	}
+	if e.observations > 0 {
+		report.Accuracy = float64(e.correct) / float64(e.observations)
+	}
+
+	for _, category := range e.categories() {
+		tp := float64(e.truePositives[category])
+		fp := float64(e.falsePositives[category])
+		fn := float64(e.falseNegatives[category])
+
+		var m Metrics
+		if tp+fp > 0 {
+			m.Precision = tp / (tp + fp)
+		}
+		if tp+fn > 0 {
+			m.Recall = tp / (tp + fn)
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		report.Categories[category] = m
+	}
+
+	return report
+}
+
+func (e *StreamEvaluator) categories() []string {
+	seen := make(map[string]bool)
+	for _, tallies := range []map[string]int{e.truePositives, e.falsePositives, e.falseNegatives} {
+		for category := range tallies {
+			seen[category] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for category := range seen {
+		keys = append(keys, category)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// OptimalThreshold sweeps every confidence the classifier assigns to the
+// target category over the validation set and returns the threshold that
+// maximizes the F1 score of treating "confidence >= threshold" as a positive
+// prediction of target, along with that F1 score. Confidences are normalized
+// posterior probabilities. Ties prefer the lowest threshold.
+func OptimalThreshold(c *Classifier, validation []Example, target string) (threshold, f1 float64) {
+	type scored struct {
+		confidence float64
+		positive   bool
+	}
+
+	c.mu.RLock()
+	samples := make([]scored, len(validation))
+	for i, example := range validation {
+		posteriors, _ := c.posteriors(c.features(example.Text))
+		samples[i] = scored{
+			confidence: posteriors[target],
+			positive:   example.Category == target,
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].confidence < samples[j].confidence
+	})
+
+	for _, candidate := range samples {
+		var tp, fp, fn float64
+		for _, sample := range samples {
+			predicted := sample.confidence >= candidate.confidence
+			switch {
+			case predicted && sample.positive:
+				tp++
+			case predicted:
+				fp++
+			case sample.positive:
+				fn++
+			}
+		}
+
+		score := 0.0
+		if tp > 0 {
+			score = 2 * tp / (2*tp + fp + fn)
+		}
+		if score > f1 {
+			threshold, f1 = candidate.confidence, score
+		}
+	}
+
+	return threshold, f1
+}
+
+// LeaveOneOut estimates accuracy by leave-one-out cross-validation: for each
+// example a fresh classifier from newClassifier is trained on all other
+// examples and asked to classify the held-out one. The returned value is the
+// fraction of examples classified correctly. This retrains the model once per
+// example, so the cost is quadratic in the number of examples; it is meant
+// for small datasets where k-fold splits are too coarse.
+func LeaveOneOut(examples []Example, newClassifier func() *Classifier) float64 {
+	if len(examples) == 0 {
+		return 0
+	}
+
+	correct := 0
+	for i, held := range examples {
+		c := newClassifier()
+		for j, example := range examples {
+			if i != j {
+				c.TrainString(example.Text, example.Category)
+			}
+		}
+		if _, label := c.Probabilities(held.Text); label == held.Category {
+			correct++
+		}
+	}
+
+	return float64(correct) / float64(len(examples))
+}
+
+// CVReport holds the accuracy of every fold of a cross-validation and their
+// mean
+type CVReport struct {
+	Folds        []float64
+	MeanAccuracy float64
+}
+
+// CrossValidate estimates accuracy by k-fold cross-validation: the examples
+// are shuffled with the given seed, so the same seed always yields the same
+// folds, and split into folds of nearly equal size. Each fold is classified
+// by a fresh classifier from newClassifier trained on all other folds. It
+// returns an error unless 2 <= folds <= len(examples).
+func CrossValidate(examples []Example, folds int, seed int64, newClassifier func() *Classifier) (CVReport, error) {
+	if folds < 2 || folds > len(examples) {
+		return CVReport{}, fmt.Errorf("invalid number of folds: %d", folds)
+	}
+
+	// the i-th shuffled example belongs to fold i % folds
+	order := rand.New(rand.NewSource(seed)).Perm(len(examples))
+
+	report := CVReport{Folds: make([]float64, folds)}
+	for k := 0; k < folds; k++ {
+		c := newClassifier()
+		for i, index := range order {
+			if i%folds != k {
+				if err := c.TrainString(examples[index].Text, examples[index].Category); err != nil {
+					return CVReport{}, err
+				}
+			}
+		}
+
+		correct, total := 0, 0
+		for i, index := range order {
+			if i%folds == k {
+				total++
+				if _, label := c.Probabilities(examples[index].Text); label == examples[index].Category {
+					correct++
+				}
+			}
+		}
+		report.Folds[k] = float64(correct) / float64(total)
+		report.MeanAccuracy += report.Folds[k] / float64(folds)
+	}
+
+	return report, nil
+}
+
+// TrainTestSplit shuffles the examples with the given seed, so the same seed
+// always yields the same split, and partitions them into a training and a
+// test set, the latter holding testFraction of the examples rounded to the
+// nearest whole example. The examples are not modified. It panics unless
+// 0 < testFraction < 1.
+func TrainTestSplit(examples []Example, testFraction float64, seed int64) (train, test []Example) {
+	if !(testFraction > 0 && testFraction < 1) {
+		panic(fmt.Sprintf("invalid test fraction: %v", testFraction))
+	}
+
+	shuffled := make([]Example, len(examples))
+	for i, index := range rand.New(rand.NewSource(seed)).Perm(len(examples)) {
+		shuffled[i] = examples[index]
+	}
+	size := int(math.Round(testFraction * float64(len(examples))))
+	return shuffled[size:], shuffled[:size]
+}
+
+// EvalResult holds the outcome of evaluating a classifier on labeled examples.
+// Confusion[actual][predicted] counts the examples of the actual category
+// that were classified as predicted, where "" stands for no category.
+type EvalResult struct {
+	Report
+	Confusion map[string]map[string]int
+}
+
+// Evaluate classifies every example with ClassifyString and tallies the
+// results into a confusion matrix along with the accuracy and per-category
+// precision, recall and F1 score
+func (c *Classifier) Evaluate(examples []Example) (*EvalResult, error) {
+	evaluator := NewStreamEvaluator()
+	confusion := make(map[string]map[string]int)
+	for _, example := range examples {
+		predicted, err := c.ClassifyString(example.Text)
+		if err != nil {
+			return nil, err
+		}
+		evaluator.Observe(predicted, example.Category)
+		if confusion[example.Category] == nil {
+			confusion[example.Category] = make(map[string]int)
+		}
+		confusion[example.Category][predicted]++
+	}
+	return &EvalResult{Report: evaluator.Report(), Confusion: confusion}, nil
+}
+
+// String renders the confusion matrix, with a row per actual and a column per
+// predicted category, followed by the per-category metrics
+func (r *EvalResult) String() string {
+	seen := make(map[string]bool)
+	for actual, predictions := range r.Confusion {
+		seen[actual] = true
+		for predicted := range predictions {
+			seen[predicted] = true
+		}
+	}
+	categories := make([]string, 0, len(seen))
+	for category := range seen {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	label := func(category string) string {
+		if category == "" {
+			return "(none)"
+		}
+		return category
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "actual \\ predicted\t")
+	for _, category := range categories {
+		fmt.Fprintf(w, "%s\t", label(category))
+	}
+	fmt.Fprintln(w)
+	for _, actual := range categories {
+		if r.Confusion[actual] == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t", label(actual))
+		for _, predicted := range categories {
+			fmt.Fprintf(w, "%d\t", r.Confusion[actual][predicted])
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprint(w, "category\tprecision\trecall\tf1\t\n")
+	for _, category := range categories {
+		if m, ok := r.Categories[category]; ok {
+			fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%.3f\t\n", label(category), m.Precision, m.Recall, m.F1)
+		}
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "accuracy %.3f over %d examples\n", r.Accuracy, r.Observations)
+	return b.String()
+}
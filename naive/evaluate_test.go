@@ -0,0 +1,221 @@
+package naive
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamEvaluator(t *testing.T) {
+	observations := []struct {
+		Predicted string
+		Actual    string
+	}{
+		{"Dog", "Dog"},
+		{"Cat", "Dog"},
+		{"Cat", "Cat"},
+		{"Dog", "Cat"},
+		{"Dog", "Dog"},
+		{"Cat", "Cat"},
+	}
+
+	evaluator := NewStreamEvaluator()
+	for _, o := range observations[:4] {
+		evaluator.Observe(o.Predicted, o.Actual)
+	}
+
+	report := evaluator.Report()
+	if report.Observations != 4 {
+		t.Errorf("Expected %d observations; actual: %d", 4, report.Observations)
+	}
+	assertFloat(t, "accuracy", 0.5, report.Accuracy)
+	assertFloat(t, "Dog precision", 0.5, report.Categories["Dog"].Precision)
+	assertFloat(t, "Dog recall", 0.5, report.Categories["Dog"].Recall)
+	assertFloat(t, "Dog F1", 0.5, report.Categories["Dog"].F1)
+
+	for _, o := range observations[4:] {
+		evaluator.Observe(o.Predicted, o.Actual)
+	}
+
+	report = evaluator.Report()
+	assertFloat(t, "accuracy", 4.0/6.0, report.Accuracy)
+	assertFloat(t, "Cat precision", 2.0/3.0, report.Categories["Cat"].Precision)
+	assertFloat(t, "Cat recall", 2.0/3.0, report.Categories["Cat"].Recall)
+}
+
+func assertFloat(t *testing.T, name string, expected, actual float64) {
+	t.Helper()
+	if math.Abs(expected-actual) > 1e-9 {
+		t.Errorf("Expected %s %f; actual: %f", name, expected, actual)
+	}
+}
+
+func TestOptimalThreshold(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Cash offer", "Spam")
+	for i := 0; i < 3; i++ {
+		classifier.TrainString("Cash meeting", "Ham")
+		classifier.TrainString("Offer notes", "Ham")
+	}
+
+	validation := []Example{
+		{"Cash offer", "Spam"},
+		{"Offer cash", "Spam"},
+		{"Cash meeting", "Ham"},
+		{"Offer notes", "Ham"},
+	}
+
+	threshold, f1 := OptimalThreshold(classifier, validation, "Spam")
+	if threshold >= 0.5 {
+		t.Errorf("Expected a threshold below 0.5; actual: %f", threshold)
+	}
+	assertFloat(t, "F1", 1, f1)
+}
+
+func TestLeaveOneOut(t *testing.T) {
+	examples := []Example{
+		{Text: "kitty meows", Category: "Cat"},
+		{Text: "kitty purrs", Category: "Cat"},
+		{Text: "kitty meows purrs", Category: "Cat"},
+		{Text: "puppy barks", Category: "Dog"},
+		{Text: "puppy wags", Category: "Dog"},
+		{Text: "puppy barks wags", Category: "Dog"},
+	}
+
+	accuracy := LeaveOneOut(examples, func() *Classifier {
+		return New(WithProbabilityFloor(0.01))
+	})
+	assertFloat(t, "accuracy", 1, accuracy)
+
+	if actual := LeaveOneOut(nil, func() *Classifier { return New() }); actual != 0 {
+		t.Errorf("Expected 0; actual: %f", actual)
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	var examples []Example
+	for i := 0; i < 5; i++ {
+		examples = append(examples,
+			Example{Text: "kitty meows purrs", Category: "Cat"},
+			Example{Text: "puppy barks wags", Category: "Dog"},
+		)
+	}
+	newClassifier := func() *Classifier { return New() }
+
+	report, err := CrossValidate(examples, 5, 42, newClassifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Folds) != 5 {
+		t.Fatalf("Expected %d folds; actual: %d", 5, len(report.Folds))
+	}
+	for _, accuracy := range report.Folds {
+		assertFloat(t, "fold accuracy", 1, accuracy)
+	}
+	assertFloat(t, "mean accuracy", 1, report.MeanAccuracy)
+
+	examples = append(examples, Example{Text: "kitty barks", Category: "Dog"}, Example{Text: "puppy meows", Category: "Cat"})
+	first, _ := CrossValidate(examples, 3, 7, newClassifier)
+	second, _ := CrossValidate(examples, 3, 7, newClassifier)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the same seed to give the same report: %v != %v", first, second)
+	}
+
+	for _, folds := range []int{1, len(examples) + 1} {
+		if _, err := CrossValidate(examples, folds, 42, newClassifier); err == nil {
+			t.Errorf("Expected %d folds to return an error", folds)
+		}
+	}
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	examples := make([]Example, 10)
+	for i := range examples {
+		examples[i] = Example{Text: fmt.Sprint(i), Category: "Number"}
+	}
+
+	train, test := TrainTestSplit(examples, 0.3, 42)
+	if len(train) != 7 || len(test) != 3 {
+		t.Fatalf("Expected 7 and 3 examples; actual: %d and %d", len(train), len(test))
+	}
+	seen := make(map[string]bool)
+	for _, example := range append(append([]Example(nil), train...), test...) {
+		if seen[example.Text] {
+			t.Errorf("Expected %q in only one set", example.Text)
+		}
+		seen[example.Text] = true
+	}
+	if len(seen) != len(examples) {
+		t.Errorf("Expected every example in a set; actual: %d", len(seen))
+	}
+
+	again, _ := TrainTestSplit(examples, 0.3, 42)
+	if !reflect.DeepEqual(train, again) {
+		t.Errorf("Expected the same seed to give the same split: %v != %v", train, again)
+	}
+
+	for _, fraction := range []float64{0, 1, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for the fraction %v", fraction)
+				}
+			}()
+			TrainTestSplit(examples, fraction, 42)
+		}()
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	c := New()
+	c.TrainString("German Shepherd", "Dog")
+	c.TrainString("Pointer", "Dog")
+	c.TrainString("Black kitty", "Cat")
+	c.TrainString("White kitten", "Cat")
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("Guppy kitty", "Fish")
+	c.TrainString("Guppy king", "Fish")
+
+	examples := []Example{
+		{Text: "German Shepherd", Category: "Dog"},
+		{Text: "Pointer", Category: "Dog"},
+		{Text: "White kitty", Category: "Cat"},
+		{Text: "Black kitten", Category: "Cat"},
+		{Text: "Guppy king", Category: "Fish"},
+		{Text: "kitty", Category: "Fish"},
+	}
+	result, err := c.Evaluate(examples)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for _, predictions := range result.Confusion {
+		for _, count := range predictions {
+			total += count
+		}
+	}
+	if total != len(examples) {
+		t.Errorf("Expected %d; actual: %d", len(examples), total)
+	}
+	if count := result.Confusion["Fish"]["Cat"]; count != 1 {
+		t.Errorf("Expected %d; actual: %d", 1, count)
+	}
+	assertFloat(t, "accuracy", 5.0/6, result.Accuracy)
+	assertFloat(t, "cat precision", 2.0/3, result.Categories["Cat"].Precision)
+	assertFloat(t, "fish recall", 0.5, result.Categories["Fish"].Recall)
+
+	for _, expected := range []string{"actual \\ predicted", "Fish", "0.667", "accuracy 0.833 over 6 examples"} {
+		if !strings.Contains(result.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, result)
+		}
+	}
+
+	if _, err := New(WithMinTrainingDocs(1)).Evaluate(examples); !errors.Is(err, ErrInsufficientTraining) {
+		t.Errorf("Expected %v; actual: %v", ErrInsufficientTraining, err)
+	}
+}
@@ -0,0 +1,84 @@
+package naive
+
+import (
+	"math"
+	"sort"
+)
+
+// TokenContribution reports the signed influence of a single input token on
+// a category's score. Positive values argue for the category and negative
+// values against it.
+type TokenContribution struct {
+	Token     string
+	Influence float64
+}
+
+// InfluenceReport classifies the provided string and returns, for the
+// predicted category, every input token with its influence on that
+// category's score, sorted from most supporting to most opposing. Influence
+// is the token's contribution to the category's log-score, computed like
+// Explain, so it honours the configured unknown-word strategy, complement
+// scoring and token weights.
+func (c *Classifier) InfluenceReport(s string) (label string, tokens []TokenContribution) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	features := c.features(s)
+	_, label = c.probabilities(features)
+	if label == "" {
+		return "", nil
+	}
+
+	background := c.wordProbabilities(features, c.totalCount())
+	tokens = make([]TokenContribution, 0, len(features))
+	for _, feature := range features {
+		tokens = append(tokens, TokenContribution{Token: feature.word, Influence: c.logContribution(feature, label, background)})
+	}
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Influence > tokens[j].Influence
+	})
+	return label, tokens
+}
+
+// WordContribution reports the additive contribution of a word to the
+// log-score of a category
+type WordContribution struct {
+	Word            string
+	LogContribution float64
+}
+
+// Explain returns every distinct word of the provided string with its
+// contribution to the log-score of the category, summed over its occurrences
+// and sorted by decreasing magnitude with ties broken by word. The prior is
+// not attributed to any word, so the contributions sum to
+// LikelihoodScores(s)[category]. It returns nil for a category unknown to the
+// model.
+func (c *Classifier) Explain(s string, category string) []WordContribution {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.CatCount[category]; !ok {
+		return nil
+	}
+
+	features := c.features(s)
+	background := c.wordProbabilities(features, c.totalCount())
+	totals := make(map[string]float64)
+	for _, feature := range features {
+		totals[feature.word] += c.logContribution(feature, category, background)
+	}
+
+	contributions := make([]WordContribution, 0, len(totals))
+	for word, total := range totals {
+		contributions = append(contributions, WordContribution{Word: word, LogContribution: total})
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		a, b := math.Abs(contributions[i].LogContribution), math.Abs(contributions[j].LogContribution)
+		if a != b {
+			return a > b
+		}
+		return contributions[i].Word < contributions[j].Word
+	})
+	return contributions
+}
@@ -0,0 +1,83 @@
+package naive
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInfluenceReport(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("White shepherd", "Dog")
+	classifier.TrainString("White pointer", "Dog")
+	classifier.TrainString("White poodle", "Dog")
+
+	label, tokens := classifier.InfluenceReport("White kitty")
+	if label != "Cat" {
+		t.Fatalf("Expected %s; actual: %s", "Cat", label)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("Expected %d tokens; actual: %d", 2, len(tokens))
+	}
+	if tokens[0].Token != "kitty" || tokens[0].Influence <= 0 {
+		t.Errorf("Expected kitty to support the category; actual: %v", tokens[0])
+	}
+	if tokens[1].Token != "white" || tokens[1].Influence >= 0 {
+		t.Errorf("Expected white to oppose the category; actual: %v", tokens[1])
+	}
+
+	if label, tokens := New().InfluenceReport("White kitty"); label != "" || tokens != nil {
+		t.Errorf("Expected no report for an untrained model; actual: %s %v", label, tokens)
+	}
+
+	for _, opts := range [][]Option{{WithComplementNB()}, {WithUnknownWords(FloorUnknownWords)}} {
+		c := New(opts...)
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("White shepherd", "Dog")
+
+		input := "white kitty zebra"
+		label, tokens := c.InfluenceReport(input)
+		sum := 0.0
+		for _, token := range tokens {
+			sum += token.Influence
+		}
+		assertFloat(t, label, c.LikelihoodScores(input)[label], sum)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithTFIDF(true)}, {WithComplementNB()}} {
+		c := New(opts...)
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("White shepherd", "Dog")
+		c.TrainString("White pointer", "Dog")
+
+		input := "white kitty kitty zebra"
+		for category, score := range c.LikelihoodScores(input) {
+			contributions := c.Explain(input, category)
+			if len(contributions) != 3 {
+				t.Fatalf("Expected %d words; actual: %v", 3, contributions)
+			}
+			sum := 0.0
+			for i, contribution := range contributions {
+				sum += contribution.LogContribution
+				if i > 0 && math.Abs(contribution.LogContribution) > math.Abs(contributions[i-1].LogContribution) {
+					t.Errorf("Expected contributions sorted by magnitude; actual: %v", contributions)
+				}
+			}
+			assertFloat(t, category, score, sum)
+		}
+
+		if contributions := c.Explain(input, "Cat"); contributions[0].Word != "kitty" {
+			t.Errorf("Expected kitty to contribute most; actual: %v", contributions)
+		}
+	}
+
+	if contributions := New().Explain("kitty", "Cat"); contributions != nil {
+		t.Errorf("Expected no contributions for an unknown category; actual: %v", contributions)
+	}
+}
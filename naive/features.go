@@ -0,0 +1,198 @@
+package naive
+
+import (
+	"math"
+	"sort"
+)
+
+// CategoryScore pairs a label with a score. Depending on the method that
+// returns it, the label is either a category or a feature word.
+type CategoryScore struct {
+	Label string
+	Score float64
+}
+
+// GlobalFeatureImportance ranks every word in the model by how unevenly it is
+// spread across categories, measured as the variance of its per-category
+// probabilities. Words concentrated in a single category rank high while
+// words that occur uniformly across categories score zero. Results are sorted
+// by descending importance.
+func (c *Classifier) GlobalFeatureImportance() []CategoryScore {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories := c.getAllCategories()
+	if len(categories) == 0 {
+		return nil
+	}
+
+	scores := make([]CategoryScore, 0, len(c.Feat2cat))
+	for word := range c.Feat2cat {
+		probabilities := make([]float64, len(categories))
+		mean := 0.0
+		for i, category := range categories {
+			probabilities[i] = c.probabilityOfWordInCategory(word, category)
+			mean += probabilities[i]
+		}
+		mean /= float64(len(categories))
+
+		variance := 0.0
+		for _, p := range probabilities {
+			variance += (p - mean) * (p - mean)
+		}
+		variance /= float64(len(categories))
+
+		scores = append(scores, CategoryScore{Label: word, Score: variance})
+	}
+
+	sortScores(scores)
+	return scores
+}
+
+// sortScores orders scores descending, breaking ties by label
+func sortScores(scores []CategoryScore) {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Label < scores[j].Label
+	})
+}
+
+// MutualInformation returns, per word, the mutual information in nats between
+// the word's occurrence and the category of a token, derived from the
+// per-category token counts. Words spread evenly across categories score
+// near zero while words that identify a category score high. Cells with a
+// zero count contribute nothing, following the 0*log(0) = 0 convention.
+func (c *Classifier) MutualInformation() map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.mutualInformation()
+}
+
+// SelectByMutualInformation prunes the model to the k words with the highest
+// mutual information and returns the number of words dropped. Document
+// counts are left untouched. A negative k is treated as zero.
+func (c *Classifier) SelectByMutualInformation(k int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if k < 0 {
+		k = 0
+	}
+
+	scores := make([]CategoryScore, 0, len(c.Feat2cat))
+	for word, mi := range c.mutualInformation() {
+		scores = append(scores, CategoryScore{Label: word, Score: mi})
+	}
+	sortScores(scores)
+
+	dropped := 0
+	for i := k; i < len(scores); i++ {
+		c.removeWord(scores[i].Label)
+		dropped++
+	}
+	return dropped
+}
+
+// mutualInformation computes the mutual information of every word; callers
+// must hold the read lock
+func (c *Classifier) mutualInformation() map[string]float64 {
+	total := 0.0
+	for _, tokens := range c.catTokens {
+		total += tokens
+	}
+
+	term := func(joint, marginalWord, marginalCategory float64) float64 {
+		if joint == 0 {
+			return 0
+		}
+		return joint / total * math.Log(joint*total/(marginalWord*marginalCategory))
+	}
+
+	mi := make(map[string]float64, len(c.Feat2cat))
+	for word, counts := range c.Feat2cat {
+		occurrences := 0.0
+		for _, count := range counts {
+			occurrences += count
+		}
+
+		score := 0.0
+		for category, tokens := range c.catTokens {
+			present := counts[category]
+			score += term(present, occurrences, tokens)
+			score += term(tokens-present, total-occurrences, tokens)
+		}
+		mi[word] = score
+	}
+	return mi
+}
+
+// PrototypeFor assembles a synthetic document for the category from the words
+// that raise its score the most, i.e. the words with the highest ratio
+// between their probability within the category and their probability
+// overall. At most tokens words are returned, most influential first, and
+// only words seen in the category are considered. Words are returned in their
+// stored feature form.
+func (c *Classifier) PrototypeFor(category string, tokens int) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	scores := c.informativeWords(category, c.totalCount())
+	if tokens < len(scores) {
+		scores = scores[:tokens]
+	}
+	prototype := make([]string, len(scores))
+	for i, score := range scores {
+		prototype[i] = score.Label
+	}
+	return prototype
+}
+
+// FeatureWeight pairs a word with how strongly it indicates a category
+type FeatureWeight struct {
+	Word  string
+	Score float64
+}
+
+// MostInformativeFeatures returns, per category, the n words seen in the
+// category that are most strongly associated with it, scored like
+// PrototypeFor as the log of the ratio between the word's probability within
+// the category and its probability overall. Each category's words are sorted
+// by descending score. Words are returned in their stored feature form.
+func (c *Classifier) MostInformativeFeatures(n int) map[string][]FeatureWeight {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	totalCount := c.totalCount()
+	features := make(map[string][]FeatureWeight, len(c.CatCount))
+	for category := range c.CatCount {
+		scores := c.informativeWords(category, totalCount)
+		if n < len(scores) {
+			scores = scores[:n]
+		}
+		weights := make([]FeatureWeight, len(scores))
+		for i, score := range scores {
+			weights[i] = FeatureWeight{Word: score.Label, Score: score.Score}
+		}
+		features[category] = weights
+	}
+	return features
+}
+
+// informativeWords scores every word seen in the category by the log of the
+// ratio between its probability within the category and its probability
+// overall, sorted by descending score; callers must hold the read lock
+func (c *Classifier) informativeWords(category string, totalCount float64) []CategoryScore {
+	var scores []CategoryScore
+	for word, counts := range c.Feat2cat {
+		if counts[category] == 0 {
+			continue
+		}
+		ratio := c.probabilityOfWordInCategory(word, category) / c.probabilityOfWordInTotalWords(word, totalCount)
+		scores = append(scores, CategoryScore{Label: word, Score: math.Log(ratio)})
+	}
+	sortScores(scores)
+	return scores
+}
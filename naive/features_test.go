@@ -0,0 +1,123 @@
+package naive
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGlobalFeatureImportance(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy guppy", "Fish")
+
+	scores := classifier.GlobalFeatureImportance()
+	if len(scores) != 4 {
+		t.Fatalf("Expected %d words; actual: %d", 4, len(scores))
+	}
+
+	rank := make(map[string]int)
+	for i, score := range scores {
+		rank[score.Label] = i
+	}
+	if rank["kitty"] > rank["fluffy"] {
+		t.Errorf("Expected kitty to outrank fluffy; actual: %v", scores)
+	}
+	if scores[len(scores)-1].Label != "fluffy" || scores[len(scores)-1].Score > 1e-12 {
+		t.Errorf("Expected fluffy to rank last with no importance; actual: %v", scores[len(scores)-1])
+	}
+}
+
+func TestMutualInformation(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy puppy", "Dog")
+
+	mi := classifier.MutualInformation()
+	if mi["fluffy"] > 1e-9 {
+		t.Errorf("Expected near-zero information for fluffy; actual: %f", mi["fluffy"])
+	}
+	if mi["kitty"] <= mi["fluffy"] {
+		t.Errorf("Expected kitty to carry more information than fluffy; actual: %f <= %f", mi["kitty"], mi["fluffy"])
+	}
+
+	if dropped := classifier.SelectByMutualInformation(2); dropped != 1 {
+		t.Errorf("Expected %d dropped word; actual: %d", 1, dropped)
+	}
+	if _, ok := classifier.Feat2cat["fluffy"]; ok {
+		t.Errorf("Expected fluffy to be pruned")
+	}
+	if classifier.catTokens["Cat"] != 2 {
+		t.Errorf("Expected %v Cat tokens; actual: %v", 2, classifier.catTokens["Cat"])
+	}
+
+	if dropped := classifier.SelectByMutualInformation(-1); dropped != 2 {
+		t.Errorf("Expected %d dropped words; actual: %d", 2, dropped)
+	}
+}
+
+func TestPrototypeFor(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy white kitty meows", "Cat")
+	classifier.TrainString("Black kitty purrs", "Cat")
+	classifier.TrainString("Fluffy white shepherd barks", "Dog")
+	classifier.TrainString("Black pointer barks", "Dog")
+
+	for _, category := range []string{"Cat", "Dog"} {
+		prototype := classifier.PrototypeFor(category, 3)
+		if len(prototype) != 3 {
+			t.Fatalf("%s: expected %d tokens; actual: %v", category, 3, prototype)
+		}
+		if label, _ := classifier.ClassifyString(strings.Join(prototype, " ")); label != category {
+			t.Errorf("%s: expected the prototype %v to classify back; actual: %s", category, prototype, label)
+		}
+	}
+
+	if actual := classifier.PrototypeFor("Fish", 3); len(actual) != 0 {
+		t.Errorf("Expected no prototype for an unknown category; actual: %v", actual)
+	}
+}
+
+func TestMostInformativeFeatures(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy white kitty meows", "Cat")
+	classifier.TrainString("Black kitty purrs", "Cat")
+	classifier.TrainString("Fluffy white shepherd barks", "Dog")
+	classifier.TrainString("Black pointer barks", "Dog")
+
+	features := classifier.MostInformativeFeatures(3)
+	if len(features) != 2 {
+		t.Fatalf("Expected %d categories; actual: %v", 2, features)
+	}
+
+	// words exclusive to a category outrank the ones shared by both
+	expected := map[string][]string{
+		"Cat": {"kitty", "meows", "purrs"},
+		"Dog": {"barks", "pointer", "shepherd"},
+	}
+	for category, words := range expected {
+		weights := features[category]
+		actual := make([]string, len(weights))
+		for i, weight := range weights {
+			actual[i] = weight.Word
+			if i > 0 && weight.Score > weights[i-1].Score {
+				t.Errorf("%s: expected descending scores; actual: %v", category, weights)
+			}
+			if weight.Score <= 0 {
+				t.Errorf("%s: expected positive scores; actual: %v", category, weights)
+			}
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, words) {
+			t.Errorf("%s: expected %v; actual: %v", category, words, weights)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package naive
+
+// ConfidenceHistogram returns a copy of the distribution of top-category
+// confidences observed by Probabilities, with bucket i counting normalized
+// confidences in [i/n, (i+1)/n). It returns nil unless
+// WithConfidenceHistogram is configured.
+func (c *Classifier) ConfidenceHistogram() []int {
+	c.histMu.Lock()
+	defer c.histMu.Unlock()
+
+	if c.histogram == nil {
+		return nil
+	}
+	return append([]int(nil), c.histogram...)
+}
+
+// observeConfidence records a top-category confidence in the histogram
+func (c *Classifier) observeConfidence(confidence float64) {
+	c.histMu.Lock()
+	defer c.histMu.Unlock()
+
+	bucket := int(confidence * float64(len(c.histogram)))
+	if bucket >= len(c.histogram) {
+		bucket = len(c.histogram) - 1
+	}
+	if bucket < 0 {
+		bucket = 0
+	}
+	c.histogram[bucket]++
+}
@@ -0,0 +1,28 @@
+package naive
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConfidenceHistogram(t *testing.T) {
+	classifier := New(WithConfidenceHistogram(4))
+
+	for i := 0; i < 3; i++ {
+		classifier.TrainString("Fluffy kitty", "Cat")
+		classifier.TrainString("Fluffy puppy", "Dog")
+	}
+
+	for _, input := range []string{"Kitty", "Puppy", "Fluffy", "Kitty fluffy", "Unknown"} {
+		classifier.Probabilities(input)
+	}
+
+	expected := []int{0, 0, 2, 3}
+	if actual := classifier.ConfidenceHistogram(); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+
+	if actual := New().ConfidenceHistogram(); actual != nil {
+		t.Errorf("Expected no histogram by default; actual: %v", actual)
+	}
+}
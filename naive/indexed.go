@@ -0,0 +1,210 @@
+package naive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// The indexed layout stores every category in its own block so that a subset
+// of categories can be loaded by seeking. All fixed width integers are little
+// endian, everything else is a uvarint or a uvarint-prefixed string:
+//
+//	magic      "NBI2"
+//	uint64     length of the index
+//	index      tokenizer signature, number of categories, then per category
+//	           its name and the uint64 offset and length of its block
+//	           relative to the end of the index
+//	blocks     per category: float64 document count, number of words, then
+//	           per word its name and float64 count
+const indexedMagic = "NBI2"
+
+// SaveIndexed writes the model in a seekable layout with an index of category
+// offsets, which allows LoadCategories to read only part of a large model. It
+// is not supported for count-min sketch models since their vocabulary cannot
+// be enumerated.
+func (c *Classifier) SaveIndexed(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.sketch != nil {
+		return errors.New("count-min sketch models cannot be saved in the indexed layout")
+	}
+
+	categories := c.sortedCategories()
+	words := make(map[string][]string, len(categories))
+	for word, counts := range c.Feat2cat {
+		for category := range counts {
+			words[category] = append(words[category], word)
+		}
+	}
+	for _, list := range words {
+		sort.Strings(list)
+	}
+
+	// the size of every block is known up front, so the index can be written
+	// first and the blocks streamed after it without buffering them
+	offsets := make([]int, len(categories)+1)
+	for i, category := range categories {
+		size := 8 + uvarintLen(uint64(len(words[category])))
+		for _, word := range words[category] {
+			size += uvarintLen(uint64(len(word))) + len(word) + 8
+		}
+		offsets[i+1] = offsets[i] + size
+	}
+
+	var index bytes.Buffer
+	iw := bufio.NewWriter(&index)
+	writeString(iw, tokenizerSignature(c.Tokenizer))
+	writeUvarint(iw, uint64(len(categories)))
+	for i, category := range categories {
+		writeString(iw, category)
+		binary.Write(iw, binary.LittleEndian, uint64(offsets[i]))
+		binary.Write(iw, binary.LittleEndian, uint64(offsets[i+1]-offsets[i]))
+	}
+	iw.Flush()
+
+	out := bufio.NewWriter(w)
+	out.WriteString(indexedMagic)
+	binary.Write(out, binary.LittleEndian, uint64(index.Len()))
+	out.Write(index.Bytes())
+	for _, category := range categories {
+		binary.Write(out, binary.LittleEndian, c.CatCount[category])
+		writeUvarint(out, uint64(len(words[category])))
+		for _, word := range words[category] {
+			writeString(out, word)
+			binary.Write(out, binary.LittleEndian, c.Feat2cat[word][category])
+		}
+	}
+	return out.Flush()
+}
+
+// uvarintLen returns the number of bytes writeUvarint writes for v
+func uvarintLen(v uint64) int {
+	n := 1
+	for ; v >= 0x80; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// LoadCategories replaces the model with the requested categories of one
+// written by SaveIndexed, seeking past every other category's block. It fails
+// if a requested category is not part of the saved model.
+func (c *Classifier) LoadCategories(rs io.ReadSeeker, categories []string) error {
+	magic := make([]byte, len(indexedMagic))
+	if _, err := io.ReadFull(rs, magic); err != nil {
+		return err
+	}
+	if string(magic) != indexedMagic {
+		return ErrInvalidFormat
+	}
+
+	var length uint64
+	if err := binary.Read(rs, binary.LittleEndian, &length); err != nil {
+		return err
+	}
+
+	// bound the index and blocks by the size of the input before allocating
+	position := int64(len(indexedMagic)) + 8
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if length > uint64(size-position) {
+		return ErrInvalidFormat
+	}
+	if _, err := rs.Seek(position, io.SeekStart); err != nil {
+		return err
+	}
+
+	header := make([]byte, length)
+	if _, err := io.ReadFull(rs, header); err != nil {
+		return err
+	}
+	start := position + int64(length)
+
+	index := bufio.NewReader(bytes.NewReader(header))
+	signature, err := readString(index)
+	if err != nil {
+		return err
+	}
+	if err := c.checkSignature(signature); err != nil {
+		return err
+	}
+
+	type block struct{ offset, length uint64 }
+	numCategories, err := binary.ReadUvarint(index)
+	if err != nil {
+		return err
+	}
+	blocks := make(map[string]block, sizeHint(numCategories))
+	for i := uint64(0); i < numCategories; i++ {
+		category, err := readString(index)
+		if err != nil {
+			return err
+		}
+		var b block
+		if err := binary.Read(index, binary.LittleEndian, &b.offset); err != nil {
+			return err
+		}
+		if err := binary.Read(index, binary.LittleEndian, &b.length); err != nil {
+			return err
+		}
+		if b.offset > uint64(size-start) || b.length > uint64(size-start)-b.offset {
+			return ErrInvalidFormat
+		}
+		blocks[category] = b
+	}
+
+	feat2cat := make(map[string]map[string]float64)
+	catCount := make(map[string]float64, len(categories))
+	for _, category := range categories {
+		b, ok := blocks[category]
+		if !ok {
+			return fmt.Errorf("category not found: %s", category)
+		}
+		if _, err := rs.Seek(start+int64(b.offset), io.SeekStart); err != nil {
+			return err
+		}
+
+		r := bufio.NewReader(io.LimitReader(rs, int64(b.length)))
+		var documents float64
+		if err := binary.Read(r, binary.LittleEndian, &documents); err != nil {
+			return err
+		}
+		catCount[category] = documents
+
+		numWords, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < numWords; i++ {
+			word, err := readString(r)
+			if err != nil {
+				return err
+			}
+			var count float64
+			if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+				return err
+			}
+			if feat2cat[word] == nil {
+				feat2cat[word] = make(map[string]float64)
+			}
+			feat2cat[word][category] = count
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Feat2cat = feat2cat
+	c.CatCount = catCount
+	c.docFreq = nil
+	c.reindex()
+	return nil
+}
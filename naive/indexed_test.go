@@ -0,0 +1,112 @@
+package naive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadCategories(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("German shepherd", "Dog")
+	classifier.TrainString("White pointer", "Dog")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("Guppy king", "Fish")
+
+	var buf bytes.Buffer
+	if err := classifier.SaveIndexed(&buf); err != nil {
+		t.Fatalf("unable to save indexed model: %v", err)
+	}
+	data := buf.Bytes()
+
+	subset := New()
+	if err := subset.LoadCategories(bytes.NewReader(data), []string{"Dog", "Fish"}); err != nil {
+		t.Fatalf("unable to load categories: %v", err)
+	}
+
+	expected := map[string]float64{"Dog": 2, "Fish": 1}
+	if !reflect.DeepEqual(subset.CatCount, expected) {
+		t.Errorf("Expected %v; actual: %v", expected, subset.CatCount)
+	}
+	if _, ok := subset.Feat2cat["kitty"]; ok {
+		t.Errorf("Expected Cat-only words to be absent")
+	}
+	if !reflect.DeepEqual(subset.Feat2cat["white"], map[string]float64{"Dog": 1}) {
+		t.Errorf("Expected only the Dog count of white; actual: %v", subset.Feat2cat["white"])
+	}
+
+	full := New()
+	if err := full.LoadCategories(bytes.NewReader(data), []string{"Cat", "Dog", "Fish"}); err != nil {
+		t.Fatalf("unable to load categories: %v", err)
+	}
+	if !reflect.DeepEqual(full.Feat2cat, classifier.Feat2cat) || !reflect.DeepEqual(full.CatCount, classifier.CatCount) {
+		t.Errorf("Expected loading every category to reproduce the model")
+	}
+
+	if err := New().LoadCategories(bytes.NewReader(data), []string{"Bird"}); err == nil {
+		t.Errorf("Expected an error for an unknown category")
+	}
+
+	for i := 0; i < 5; i++ {
+		var again bytes.Buffer
+		classifier.SaveIndexed(&again)
+		if !bytes.Equal(again.Bytes(), data) {
+			t.Fatalf("Expected identical output for the same model")
+		}
+	}
+}
+
+func TestLoadCategoriesCorrupt(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("Black kitty", "Cat")
+
+	var buf bytes.Buffer
+	if err := classifier.SaveIndexed(&buf); err != nil {
+		t.Fatalf("unable to save indexed model: %v", err)
+	}
+
+	data := append([]byte(nil), buf.Bytes()...)
+	binary.LittleEndian.PutUint64(data[len(indexedMagic):], 1<<62)
+	if err := New().LoadCategories(bytes.NewReader(data), []string{"Cat"}); err != ErrInvalidFormat {
+		t.Errorf("Expected %v; actual: %v", ErrInvalidFormat, err)
+	}
+
+	data = append([]byte(nil), buf.Bytes()...)
+	indexEnd := len(indexedMagic) + 8 + int(binary.LittleEndian.Uint64(data[len(indexedMagic):]))
+	binary.LittleEndian.PutUint64(data[indexEnd-16:], 1<<40)
+	if err := New().LoadCategories(bytes.NewReader(data), []string{"Cat"}); err != ErrInvalidFormat {
+		t.Errorf("Expected %v; actual: %v", ErrInvalidFormat, err)
+	}
+}
+
+func TestSaveIndexedLongWords(t *testing.T) {
+	classifier := New()
+	long := strings.Repeat("kitty", 40)
+	classifier.TrainString("White "+long, "Cat")
+	for i := 0; i < 200; i++ {
+		classifier.AddObservations(fmt.Sprint("word", i), "Dog", 1, 0)
+	}
+
+	var buf bytes.Buffer
+	if err := classifier.SaveIndexed(&buf); err != nil {
+		t.Fatalf("unable to save indexed model: %v", err)
+	}
+	loaded := New()
+	if err := loaded.LoadCategories(bytes.NewReader(buf.Bytes()), []string{"Dog", "Cat"}); err != nil {
+		t.Fatalf("unable to load categories: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Feat2cat, classifier.Feat2cat) {
+		t.Errorf("Expected the blocks to round-trip")
+	}
+
+	for _, v := range []uint64{0, 127, 128, 1 << 14, 1<<63 + 1} {
+		buf := make([]byte, binary.MaxVarintLen64)
+		if expected := binary.PutUvarint(buf, v); uvarintLen(v) != expected {
+			t.Errorf("%d: expected %d; actual: %d", v, expected, uvarintLen(v))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package naive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// TrainDir trains the classifier from a directory laid out as
+// root/<category>/<document>. Every immediate subdirectory of root names a
+// category and every regular file beneath it is trained as one document of
+// that category. Files directly inside root and non-regular files are
+// skipped. The number of trained documents is returned along with the first
+// error encountered, which includes the offending path.
+func (c *Classifier) TrainDir(root string) (int, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return 0, err
+	}
+
+	documents := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		category := entry.Name()
+		err := filepath.WalkDir(filepath.Join(root, category), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			defer f.Close()
+
+			if err := c.Train(f, category); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			documents++
+			return nil
+		})
+		if err != nil {
+			return documents, err
+		}
+	}
+
+	return documents, nil
+}
+
+// TrainCSV trains one document per CSV record read from r, taking the text
+// and the category from the given zero-based columns. The first record is
+// skipped when hasHeader is set. Records may have differing numbers of
+// fields, but one lacking either column stops training with an error naming
+// its line. Negative columns are rejected before anything is read. The number
+// of trained records is returned along with the first error encountered.
+func (c *Classifier) TrainCSV(r io.Reader, textCol, categoryCol int, hasHeader bool) (int, error) {
+	if textCol < 0 || categoryCol < 0 {
+		return 0, fmt.Errorf("invalid columns %d and %d", textCol, categoryCol)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	if hasHeader {
+		if _, err := reader.Read(); err != nil {
+			if err == io.EOF {
+				return 0, nil
+			}
+			return 0, err
+		}
+	}
+
+	trained := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return trained, nil
+		}
+		if err != nil {
+			return trained, err
+		}
+
+		if textCol >= len(record) || categoryCol >= len(record) {
+			line, _ := reader.FieldPos(0)
+			return trained, fmt.Errorf("line %d: %d fields, need columns %d and %d", line, len(record), textCol, categoryCol)
+		}
+		if err := c.TrainString(record[textCol], record[categoryCol]); err != nil {
+			line, _ := reader.FieldPos(0)
+			return trained, fmt.Errorf("line %d: %w", line, err)
+		}
+		trained++
+	}
+}
@@ -0,0 +1,84 @@
+package naive
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrainDir(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Dog/shepherd.txt": "German shepherd",
+		"Dog/pointer.txt":  "Pointer",
+		"Cat/black.txt":    "Black kitty",
+		"Cat/nested/white": "White kitten",
+		"README":           "not a category",
+	}
+	for name, text := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	classifier := New()
+	documents, err := classifier.TrainDir(root)
+	if err != nil {
+		t.Fatalf("unable to train directory: %v", err)
+	}
+	if documents != 4 {
+		t.Errorf("Expected %d documents; actual: %d", 4, documents)
+	}
+	if classifier.CatCount["Dog"] != 2 || classifier.CatCount["Cat"] != 2 {
+		t.Errorf("Expected 2 documents per category; actual: %v", classifier.CatCount)
+	}
+	if _, topResult := classifier.Probabilities("Black kitty"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+}
+
+func TestTrainDirMissing(t *testing.T) {
+	if _, err := New().TrainDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("Expected an error for a missing directory")
+	}
+}
+
+func TestTrainCSV(t *testing.T) {
+	data := `category,text
+Dog,German shepherd
+Cat,"Black kitty, white paws"
+Dog,Pointer,extra
+`
+	classifier := New()
+	trained, err := classifier.TrainCSV(strings.NewReader(data), 1, 0, true)
+	if err != nil {
+		t.Fatalf("unable to train: %v", err)
+	}
+	if trained != 3 {
+		t.Errorf("Expected %d; actual: %d", 3, trained)
+	}
+	if categories := classifier.Categories(); !reflect.DeepEqual(categories, []string{"Cat", "Dog"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"Cat", "Dog"}, categories)
+	}
+	if _, topResult := classifier.Probabilities("White paws"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+
+	trained, err = New().TrainCSV(strings.NewReader("Dog,Shepherd\nCat\n"), 1, 0, false)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error on line 2; actual: %v", err)
+	}
+	if trained != 1 {
+		t.Errorf("Expected %d; actual: %d", 1, trained)
+	}
+
+	if _, err := New().TrainCSV(strings.NewReader("Shepherd,Dog\n"), -1, 1, false); err == nil {
+		t.Errorf("Expected an error for a negative column")
+	}
+}
@@ -0,0 +1,75 @@
+package naive
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/carautenbach/classifier"
+)
+
+// jsonSchemaVersion identifies the layout of the JSON model format and is
+// incremented whenever it changes incompatibly
+const jsonSchemaVersion = 1
+
+// jsonModel is the JSON encoded form of a Classifier
+type jsonModel struct {
+	Version   int                           `json:"version"`
+	Signature string                        `json:"signature"`
+	Feat2cat  map[string]map[string]float64 `json:"feat2cat"`
+	CatCount  map[string]float64            `json:"catCount"`
+	DocFreq   map[string]float64            `json:"docFreq,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding the model's counts along
+// with a schema version and the tokenizer signature. Map keys are sorted by
+// encoding/json, so models trained on the same data encode identically.
+func (c *Classifier) MarshalJSON() ([]byte, error) {
+	snapshot, err := c.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonModel{
+		Version:   jsonSchemaVersion,
+		Signature: snapshot.model.Signature,
+		Feat2cat:  snapshot.model.Feat2cat,
+		CatCount:  snapshot.model.CatCount,
+		DocFreq:   snapshot.model.DocFreq,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the model's counts
+// with ones encoded by MarshalJSON. The configured tokenizer is kept, or the
+// standard tokenizer is used when there is none, and checked against the one
+// the model was saved with. Options are not encoded, so unmarshal into a
+// classifier created by New to configure them. An unknown schema version fails
+// with ErrUnsupportedVersion.
+func (c *Classifier) UnmarshalJSON(data []byte) error {
+	var m jsonModel
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if m.Version != jsonSchemaVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, m.Version)
+	}
+	if c.Tokenizer == nil {
+		c.Tokenizer = classifier.NewTokenizer()
+	}
+	if err := c.checkSignature(m.Signature); err != nil {
+		return err
+	}
+	if m.Feat2cat == nil {
+		m.Feat2cat = make(map[string]map[string]float64)
+	}
+	if m.CatCount == nil {
+		m.CatCount = make(map[string]float64)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Feat2cat = m.Feat2cat
+	c.CatCount = m.CatCount
+	c.docFreq = m.DocFreq
+	c.reindex()
+	return nil
+}
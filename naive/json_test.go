@@ -0,0 +1,51 @@
+package naive
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	trained := New()
+	trained.TrainString("The quick brown fox jumps", "Animal")
+	trained.TrainString("The lazy dog sleeps", "Animal")
+	trained.TrainString("Stocks rally as markets open", "Finance")
+
+	data, err := json.Marshal(trained)
+	if err != nil {
+		t.Fatalf("unable to marshal: %v", err)
+	}
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("unable to unmarshal: %v", err)
+	}
+
+	for _, input := range []string{"quick dog", "markets rally", "unseen words"} {
+		expected, expectedTop := trained.Probabilities(input)
+		actual, actualTop := loaded.Probabilities(input)
+		if expectedTop != actualTop {
+			t.Errorf("Expected %v; actual: %v", expectedTop, actualTop)
+		}
+		for category, p := range expected {
+			if actual[category] != p {
+				t.Errorf("Expected %v; actual: %v", p, actual[category])
+			}
+		}
+	}
+
+	again, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatalf("unable to marshal: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("Expected %s; actual: %s", data, again)
+	}
+}
+
+func TestJSONVersion(t *testing.T) {
+	err := json.Unmarshal([]byte(`{"version": 99, "feat2cat": {}, "catCount": {}}`), New())
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected %v; actual: %v", ErrUnsupportedVersion, err)
+	}
+}
@@ -0,0 +1,365 @@
+package naive
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ErrClosed is returned when a FrozenClassifier is used after Close
+var ErrClosed = errors.New("classifier is closed")
+
+// The mapped layout is designed to be read in place from a memory-mapped
+// file. All numbers are little endian:
+//
+//	header     magic "NBM2", uint32 category, word and entry counts, then
+//	           uint32 offset and length of the tokenizer signature
+//	categories per category: uint32 name offset, uint32 name length,
+//	           float64 document count, float64 token count
+//	words      sorted by name, per word: uint32 name offset, uint32 name
+//	           length, uint32 first entry, uint32 entry count
+//	entries    per (word, category): uint32 category index, float64 count
+//	strings    the tokenizer signature and the category and word names
+const (
+	mappedMagic        = "NBM2"
+	mappedHeaderSize   = 24
+	mappedCategorySize = 24
+	mappedWordSize     = 16
+	mappedEntrySize    = 12
+)
+
+// maxMappedSize is the largest file the mapped layout can address with its
+// uint32 offsets
+var maxMappedSize int64 = math.MaxUint32
+
+// FrozenClassifier provides read-only classification over a model that is
+// memory-mapped from disk, so the operating system can share its pages
+// between every process that opens the same file. It must be closed to
+// release the mapping.
+type FrozenClassifier struct {
+	mu    sync.RWMutex
+	c     *Classifier
+	close func() error
+}
+
+// OpenMapped memory-maps a model written by SaveMapped into a classifier
+// configured with opts, like Load. Only the small category table is copied
+// onto the heap; word counts are read directly from the mapping. Every offset
+// in the file is validated here, so a corrupt file is rejected with
+// ErrInvalidFormat instead of failing during classification.
+func OpenMapped(path string, opts ...Option) (*FrozenClassifier, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMappedModel(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+
+	c := New(opts...)
+	if signature, ok := m.str(16); !ok {
+		unmap()
+		return nil, ErrInvalidFormat
+	} else if err := c.checkSignature(signature); err != nil {
+		unmap()
+		return nil, err
+	}
+
+	c.mapped = m
+	for i, category := range m.categories {
+		offset := mappedHeaderSize + i*mappedCategorySize
+		c.CatCount[category] = math.Float64frombits(binary.LittleEndian.Uint64(data[offset+8:]))
+		c.catTokens[category] = math.Float64frombits(binary.LittleEndian.Uint64(data[offset+16:]))
+	}
+
+	return &FrozenClassifier{c: c, close: unmap}, nil
+}
+
+// Probabilities behaves like Classifier.Probabilities; it returns no scores
+// once the classifier is closed
+func (f *FrozenClassifier) Probabilities(s string) (map[string]float64, string) {
+	probabilities, topCategory, _ := f.ProbabilitiesContext(context.Background(), s)
+	return probabilities, topCategory
+}
+
+// ProbabilitiesContext behaves like Classifier.ProbabilitiesContext and
+// returns ErrClosed once the classifier is closed
+func (f *FrozenClassifier) ProbabilitiesContext(ctx context.Context, s string) (map[string]float64, string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.close == nil {
+		return nil, "", ErrClosed
+	}
+	return f.c.ProbabilitiesContext(ctx, s)
+}
+
+// Close releases the memory mapping once no classification is running;
+// later calls to Close or Probabilities return ErrClosed
+func (f *FrozenClassifier) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.close == nil {
+		return ErrClosed
+	}
+
+	f.c.mu.Lock()
+	f.c.mapped = nil
+	f.c.mu.Unlock()
+
+	err := f.close()
+	f.close = nil
+	return err
+}
+
+// SaveMapped writes the model in the layout expected by OpenMapped. It is not
+// supported for count-min sketch models since their vocabulary cannot be
+// enumerated, nor for models whose file would reach 4 GiB, the limit of the
+// layout's 32-bit offsets.
+func (c *Classifier) SaveMapped(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.sketch != nil {
+		return errors.New("count-min sketch models cannot be saved in the mapped layout")
+	}
+
+	categories := c.sortedCategories()
+	index := make(map[string]uint32, len(categories))
+	for i, category := range categories {
+		index[category] = uint32(i)
+	}
+
+	words := make([]string, 0, len(c.Feat2cat))
+	entries := 0
+	for word, counts := range c.Feat2cat {
+		words = append(words, word)
+		entries += len(counts)
+	}
+	sort.Strings(words)
+
+	stringsOffset := mappedHeaderSize + len(categories)*mappedCategorySize +
+		len(words)*mappedWordSize + entries*mappedEntrySize
+	signature := tokenizerSignature(c.Tokenizer)
+
+	// every offset, length and count written below is bounded by the size
+	size := stringsOffset + len(signature)
+	for _, category := range categories {
+		size += len(category)
+	}
+	for _, word := range words {
+		size += len(word)
+	}
+	if int64(size) > maxMappedSize {
+		return fmt.Errorf("model of %d bytes exceeds the %d byte limit of the mapped layout", size, maxMappedSize)
+	}
+
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, mappedCategorySize)
+	put32 := func(v uint32) {
+		binary.LittleEndian.PutUint32(buf, v)
+		bw.Write(buf[:4])
+	}
+	putFloat := func(v float64) {
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+		bw.Write(buf[:8])
+	}
+
+	bw.WriteString(mappedMagic)
+	put32(uint32(len(categories)))
+	put32(uint32(len(words)))
+	put32(uint32(entries))
+
+	put32(uint32(stringsOffset))
+	put32(uint32(len(signature)))
+
+	offset := stringsOffset + len(signature)
+	for _, category := range categories {
+		put32(uint32(offset))
+		put32(uint32(len(category)))
+		putFloat(c.CatCount[category])
+		putFloat(c.catTokens[category])
+		offset += len(category)
+	}
+
+	first := 0
+	for _, word := range words {
+		put32(uint32(offset))
+		put32(uint32(len(word)))
+		put32(uint32(first))
+		put32(uint32(len(c.Feat2cat[word])))
+		offset += len(word)
+		first += len(c.Feat2cat[word])
+	}
+
+	for _, word := range words {
+		counts := c.Feat2cat[word]
+		for _, category := range sortedKeys(counts) {
+			put32(index[category])
+			putFloat(counts[category])
+		}
+	}
+
+	bw.WriteString(signature)
+	for _, category := range categories {
+		bw.WriteString(category)
+	}
+	for _, word := range words {
+		bw.WriteString(word)
+	}
+
+	return bw.Flush()
+}
+
+// mappedModel reads word counts in place from the mapped layout
+type mappedModel struct {
+	data       []byte
+	categories []string
+	index      map[string]uint32
+	numWords   int
+	words      int
+	entries    int
+}
+
+func newMappedModel(data []byte) (*mappedModel, error) {
+	if len(data) < mappedHeaderSize || string(data[:4]) != mappedMagic {
+		return nil, ErrInvalidFormat
+	}
+
+	numCategories := int(binary.LittleEndian.Uint32(data[4:]))
+	numWords := int(binary.LittleEndian.Uint32(data[8:]))
+	numEntries := int(binary.LittleEndian.Uint32(data[12:]))
+
+	m := &mappedModel{
+		data:     data,
+		index:    make(map[string]uint32, numCategories),
+		numWords: numWords,
+		words:    mappedHeaderSize + numCategories*mappedCategorySize,
+	}
+	m.entries = m.words + numWords*mappedWordSize
+	if m.entries+numEntries*mappedEntrySize > len(data) {
+		return nil, ErrInvalidFormat
+	}
+
+	for i := 0; i < numCategories; i++ {
+		category, ok := m.str(mappedHeaderSize + i*mappedCategorySize)
+		if !ok {
+			return nil, ErrInvalidFormat
+		}
+		m.categories = append(m.categories, category)
+		m.index[category] = uint32(i)
+	}
+
+	// validate the word table so lookups never read outside the mapping
+	previous := ""
+	for i := 0; i < numWords; i++ {
+		position := m.words + i*mappedWordSize
+		word, ok := m.str(position)
+		if !ok || (i > 0 && word <= previous) {
+			return nil, ErrInvalidFormat
+		}
+		previous = word
+
+		first := uint64(binary.LittleEndian.Uint32(data[position+8:]))
+		count := uint64(binary.LittleEndian.Uint32(data[position+12:]))
+		if first+count > uint64(numEntries) {
+			return nil, ErrInvalidFormat
+		}
+	}
+	for i := 0; i < numEntries; i++ {
+		if int(binary.LittleEndian.Uint32(data[m.entries+i*mappedEntrySize:])) >= numCategories {
+			return nil, ErrInvalidFormat
+		}
+	}
+
+	return m, nil
+}
+
+// str reads the string referenced by the offset/length pair at position
+func (m *mappedModel) str(position int) (string, bool) {
+	offset := uint64(binary.LittleEndian.Uint32(m.data[position:]))
+	length := uint64(binary.LittleEndian.Uint32(m.data[position+4:]))
+	if offset+length > uint64(len(m.data)) {
+		return "", false
+	}
+	return string(m.data[offset : offset+length]), true
+}
+
+// word returns the name of the i-th word, whose offsets were validated by
+// newMappedModel
+func (m *mappedModel) word(i int) string {
+	position := m.words + i*mappedWordSize
+	offset := int(binary.LittleEndian.Uint32(m.data[position:]))
+	length := int(binary.LittleEndian.Uint32(m.data[position+4:]))
+	return string(m.data[offset : offset+length])
+}
+
+// lookup returns the entry range of the word
+func (m *mappedModel) lookup(word string) (first, count int, ok bool) {
+	i := sort.Search(m.numWords, func(i int) bool {
+		return m.word(i) >= word
+	})
+	if i == m.numWords || m.word(i) != word {
+		return 0, 0, false
+	}
+
+	position := m.words + i*mappedWordSize
+	first = int(binary.LittleEndian.Uint32(m.data[position+8:]))
+	count = int(binary.LittleEndian.Uint32(m.data[position+12:]))
+	return first, count, true
+}
+
+func (m *mappedModel) entry(i int) (uint32, float64) {
+	position := m.entries + i*mappedEntrySize
+	return binary.LittleEndian.Uint32(m.data[position:]), math.Float64frombits(binary.LittleEndian.Uint64(m.data[position+4:]))
+}
+
+func (m *mappedModel) count(word, category string) float64 {
+	index, ok := m.index[category]
+	if !ok {
+		return 0
+	}
+	first, n, ok := m.lookup(word)
+	if !ok {
+		return 0
+	}
+	for i := first; i < first+n; i++ {
+		if c, count := m.entry(i); c == index {
+			return count
+		}
+	}
+	return 0
+}
+
+func (m *mappedModel) total(word string) float64 {
+	first, n, ok := m.lookup(word)
+	if !ok {
+		return 0
+	}
+	sum := 0.0
+	for i := first; i < first+n; i++ {
+		_, count := m.entry(i)
+		sum += count
+	}
+	return sum
+}
+
+// readFile is the fallback for platforms without memory mapping support
+func readFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,153 @@
+package naive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carautenbach/classifier"
+)
+
+func TestOpenMapped(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("German shepherd", "Dog")
+	classifier.TrainString("White pointer", "Dog")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("Guppy king", "Fish")
+
+	path := filepath.Join(t.TempDir(), "model.nbm")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := classifier.SaveMapped(f); err != nil {
+		t.Fatalf("unable to save mapped model: %v", err)
+	}
+	f.Close()
+
+	frozen, err := OpenMapped(path)
+	if err != nil {
+		t.Fatalf("unable to open mapped model: %v", err)
+	}
+
+	for _, input := range []string{"White kitty", "German pointer", "Guppy", "Black"} {
+		expected, expectedTop := classifier.Probabilities(input)
+		actual, actualTop := frozen.Probabilities(input)
+		if expectedTop != actualTop {
+			t.Errorf("%s: expected %s; actual: %s", input, expectedTop, actualTop)
+		}
+		for category, p := range expected {
+			if actual[category] != p {
+				t.Errorf("%s: expected %s probability %f; actual: %f", input, category, p, actual[category])
+			}
+		}
+	}
+
+	if err := frozen.Close(); err != nil {
+		t.Fatalf("unable to close mapped model: %v", err)
+	}
+	if maps, err := os.ReadFile("/proc/self/maps"); err == nil && strings.Contains(string(maps), path) {
+		t.Errorf("Expected the mapping to be released on close")
+	}
+	if err := frozen.Close(); err != ErrClosed {
+		t.Errorf("Expected %v; actual: %v", ErrClosed, err)
+	}
+	if probabilities, topResult := frozen.Probabilities("White kitty"); probabilities != nil || topResult != "" {
+		t.Errorf("Expected no scores after close; actual: %v %s", probabilities, topResult)
+	}
+}
+
+func TestOpenMappedOptions(t *testing.T) {
+	tokenizer := classifier.NewTokenizer(classifier.WithLowercase(false))
+	c := New(WithTokenizer(tokenizer))
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("white shepherd", "Dog")
+
+	var buf bytes.Buffer
+	if err := c.SaveMapped(&buf); err != nil {
+		t.Fatalf("unable to save mapped model: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "model.nbm")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenMapped(path, WithStrictTokenizer()); !errors.Is(err, ErrTokenizerMismatch) {
+		t.Errorf("Expected %v; actual: %v", ErrTokenizerMismatch, err)
+	}
+
+	frozen, err := OpenMapped(path, WithTokenizer(tokenizer), WithStrictTokenizer())
+	if err != nil {
+		t.Fatalf("unable to open mapped model: %v", err)
+	}
+	defer frozen.Close()
+	if _, topResult := frozen.Probabilities("White"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+}
+
+func TestOpenMappedCorrupt(t *testing.T) {
+	c := New()
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("Black shepherd", "Dog")
+
+	var buf bytes.Buffer
+	if err := c.SaveMapped(&buf); err != nil {
+		t.Fatalf("unable to save mapped model: %v", err)
+	}
+	words := mappedHeaderSize + 2*mappedCategorySize
+
+	corruptions := map[string]func(data []byte){
+		"word offset": func(data []byte) {
+			binary.LittleEndian.PutUint32(data[words:], uint32(len(data)))
+		},
+		"entry range": func(data []byte) {
+			binary.LittleEndian.PutUint32(data[words+12:], 1000)
+		},
+		"category index": func(data []byte) {
+			binary.LittleEndian.PutUint32(data[words+4*mappedWordSize:], 7)
+		},
+	}
+	for name, corrupt := range corruptions {
+		data := append([]byte(nil), buf.Bytes()...)
+		corrupt(data)
+		path := filepath.Join(t.TempDir(), "model.nbm")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenMapped(path); err != ErrInvalidFormat {
+			t.Errorf("%s: expected %v; actual: %v", name, ErrInvalidFormat, err)
+		}
+	}
+}
+
+func TestOpenMappedInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.nbm")
+	if err := os.WriteFile(path, []byte("not a model"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenMapped(path); err != ErrInvalidFormat {
+		t.Errorf("Expected %v; actual: %v", ErrInvalidFormat, err)
+	}
+}
+
+func TestSaveMappedTooLarge(t *testing.T) {
+	c := New()
+	c.TrainString("White kitty", "Cat")
+
+	defer func(limit int64) { maxMappedSize = limit }(maxMappedSize)
+	maxMappedSize = 64
+
+	var buf bytes.Buffer
+	if err := c.SaveMapped(&buf); err == nil {
+		t.Errorf("Expected an error for a model exceeding the mapped layout")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be written; actual: %d bytes", buf.Len())
+	}
+}
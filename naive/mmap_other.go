@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package naive
+
+// mapFile reads the whole file into memory on platforms without mmap
+func mapFile(path string) ([]byte, func() error, error) {
+	return readFile(path)
+}
@@ -2,43 +2,202 @@ package naive
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/carautenbach/classifier"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
 )
 
+var _ classifier.Classifier = (*Classifier)(nil)
+
+// ErrInsufficientTraining is returned when classifying with a model that has
+// not yet been trained on the minimum number of documents configured with
+// WithMinTrainingDocs
+var ErrInsufficientTraining = errors.New("insufficient training documents")
+
+// ErrNotTrained is returned when untraining a document that the model's counts
+// show was never trained into the category
+var ErrNotTrained = errors.New("document was not trained")
+
 // Classifier implements a naive bayes classifier
 type Classifier struct {
-	Feat2cat  map[string]map[string]int
-	CatCount  map[string]int
+	Feat2cat  map[string]map[string]float64
+	CatCount  map[string]float64
 	Tokenizer classifier.Tokenizer
-	mu        sync.RWMutex
+	// Alpha is the additive (Laplace/Lidstone) smoothing strength applied to
+	// every word probability as (count + Alpha) / (documents + Alpha * V),
+	// where V is the vocabulary size; 0 disables smoothing. A word unknown
+	// to the model thus scores Alpha / (documents + Alpha * V), which
+	// favours categories with fewer documents; WithUnknownWords can ignore
+	// such words instead.
+	Alpha float64
+	// Concurrency is the number of goroutines categories are scored across
+	// during classification; values below 1 score serially. Results do not
+	// depend on it.
+	Concurrency int
+	// Logger receives diagnostics such as scoring times and per-category
+	// scores; nil keeps the classifier silent
+	Logger *log.Logger
+
+	mu         sync.RWMutex
+	floor      float64
+	sketch     *countMinSketch
+	mapped     *mappedModel
+	display    float64
+	adaptive   bool
+	ngrams     map[int]float64
+	retain     bool
+	folded     map[string]map[string]bool
+	strict     bool
+	unique     bool
+	tiebreaker *Classifier
+	histogram  []int
+	histMu     sync.Mutex
+	prior      priorMode
+	warm       *warmCache
+	recency    *recency
+	minDocs    int
+	normalized bool
+	decay      float64
+	hook       func(input, label string, confidence float64)
+	earlyStop  float64
+	salt       *string
+	examples   []Example
+	catTokens  map[string]float64
+	docFreq    map[string]float64
+	tfidf      bool
+	complement bool
+	unknown    UnknownWordStrategy
+	fallback   string
+	priors     map[string]float64
+	level      int
+	shared     bool
+	owned      map[string]bool
 }
 
-// New initializes a new naive Classifier using the standard tokenizer
-func New() *Classifier {
+// New initializes a new naive Classifier; it is equivalent to
+// NewWithOptions
+func New(opts ...Option) *Classifier {
+	return NewWithOptions(opts...)
+}
+
+// NewWithOptions initializes a new naive Classifier configured by opts, which
+// are applied in order. Without options it uses the standard tokenizer,
+// Laplace smoothing (Alpha 1), one scoring goroutine per CPU and no logger.
+func NewWithOptions(opts ...Option) *Classifier {
 	c := &Classifier{
-		Feat2cat:  make(map[string]map[string]int),
-		CatCount:  make(map[string]int),
-		Tokenizer: classifier.NewTokenizer(),
+		Feat2cat:    make(map[string]map[string]float64),
+		CatCount:    make(map[string]float64),
+		Tokenizer:   classifier.NewTokenizer(),
+		Alpha:       1,
+		Concurrency: runtime.NumCPU(),
+		catTokens:   make(map[string]float64),
+		docFreq:     make(map[string]float64),
+		level:       gzip.DefaultCompression,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 	return c
 }
 
+// SetAlpha sets the additive smoothing strength, see Alpha. It panics if
+// alpha is negative.
+func (c *Classifier) SetAlpha(alpha float64) {
+	if !(alpha >= 0) {
+		panic(fmt.Sprintf("invalid alpha: %v", alpha))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Alpha = alpha
+}
+
+// SetPriors overrides the prior probability of the given categories, e.g. to
+// correct for categories occurring at different rates in production than in
+// the training set. Categories without an override keep the prior they would
+// otherwise have, and all priors are renormalized to sum to 1. Priors for
+// categories the model does not know are kept until the category is trained.
+// A nil or empty map removes every override. It returns an error, leaving the
+// priors unchanged, if any prior is negative or not finite.
+func (c *Classifier) SetPriors(priors map[string]float64) error {
+	for category, prior := range priors {
+		if !(prior >= 0) || math.IsInf(prior, 1) {
+			return fmt.Errorf("invalid prior for %s: %v", category, prior)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.priors = nil
+	if len(priors) > 0 {
+		c.priors = copyCounts(priors)
+	}
+	return nil
+}
+
 // Train provides supervisory training to the classifier
 func (c *Classifier) Train(r io.Reader, category string) error {
+	return c.train(r, category, 1, time.Time{})
+}
+
+// TrainWeighted behaves like Train but counts the document weight times, so
+// confident examples can count more than others. Training with weight 2 is
+// equivalent to training the document twice; fractional weights are allowed.
+// The weight must be positive and finite.
+func (c *Classifier) TrainWeighted(r io.Reader, category string, weight float64) error {
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return fmt.Errorf("invalid weight: %v", weight)
+	}
+	return c.train(r, category, weight, time.Time{})
+}
+
+// train trains the document with the given weight, recording it as observed
+// at t unless t is zero
+func (c *Classifier) train(r io.Reader, category string, weight float64, t time.Time) error {
+	var text []byte
+	if c.retain {
+		var err error
+		if text, err = io.ReadAll(r); err != nil {
+			return err
+		}
+		r = bytes.NewReader(text)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for word := range c.Tokenizer.Tokenize(r) {
-		c.addWord(word, category)
+	features := c.featuresOf(c.Tokenizer.Tokenize(r))
+	for _, feature := range features {
+		c.addWordCount(feature.word, category, weight)
+	}
+	c.addDocument(features, weight)
+	if c.recency != nil && !t.IsZero() {
+		c.recency.add(features, category, weight, t)
 	}
 
-	c.CatCount[category]++
+	c.unshare()
+	c.CatCount[category] += weight
+	c.warm = nil
+	if c.retain {
+		c.examples = append(c.examples, Example{Text: string(text), Category: category})
+	}
 	return nil
 }
 
@@ -47,42 +206,1006 @@ func (c *Classifier) TrainString(title string, category string) error {
 	return c.Train(AsReader(title), category)
 }
 
+// Untrain reverses training the document read from r into the category,
+// removing words and categories whose counts drop to zero. Training and then
+// untraining a document restores the model exactly. The model is left
+// unchanged and ErrNotTrained is returned if the counts could not have come
+// from training the document, since removing them would make them negative.
+// Under WithHalfLife the document's timestamp is unknown, so any timestamped
+// counts that are removed are taken at their average age. Count-min sketch
+// models cannot be untrained.
+func (c *Classifier) Untrain(r io.Reader, category string) error {
+	var text []byte
+	if c.retain {
+		var err error
+		if text, err = io.ReadAll(r); err != nil {
+			return err
+		}
+		r = bytes.NewReader(text)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sketch != nil {
+		return errors.New("count-min sketch models cannot be untrained")
+	}
+
+	counts := make(map[string]float64)
+	for _, feature := range c.featuresOf(c.Tokenizer.Tokenize(r)) {
+		counts[feature.word]++
+	}
+	if c.CatCount[category] < 1 {
+		return fmt.Errorf("%w: no documents in category %s", ErrNotTrained, category)
+	}
+	for word, count := range counts {
+		if c.Feat2cat[word][category] < count {
+			return fmt.Errorf("%w: %q occurs %v times in category %s", ErrNotTrained, word, c.Feat2cat[word][category], category)
+		}
+	}
+
+	for word, count := range counts {
+		c.removeDocumentWord(word, 1)
+		c.removeWordCount(word, category, count)
+	}
+	c.unshare()
+	c.CatCount[category]--
+	if c.CatCount[category] == 0 {
+		delete(c.CatCount, category)
+	}
+	if c.recency != nil {
+		c.recency.limitDocs(category, c.CatCount[category])
+	}
+	c.warm = nil
+	if c.retain {
+		for i, example := range c.examples {
+			if example.Category == category && example.Text == string(text) {
+				c.examples = append(c.examples[:i], c.examples[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// UntrainString reverses training the string into the category, see Untrain
+func (c *Classifier) UntrainString(title string, category string) error {
+	return c.Untrain(AsReader(title), category)
+}
+
+// AddObservations bulk-loads pre-aggregated counts, adding count occurrences
+// of the word to the category along with documents training documents, as
+// if the corresponding documents had been trained one by one. The word is
+// taken as given, so it must already be in the form the tokenizer produces,
+// and is hashed like any other feature under WithFeatureHashingSalt. Each
+// occurrence counts towards the word's document frequency for WithTFIDF as
+// if it came from a separate document. A count of 0 only adds documents. It panics if count or documents is
+// negative.
+func (c *Classifier) AddObservations(word, category string, count float64, documents float64) {
+	if !(count >= 0 && documents >= 0) {
+		panic(fmt.Sprintf("negative observations: count %v, documents %v", count, documents))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if count > 0 {
+		hashed := c.hashWord(word)
+		c.addWordCount(hashed, category, count)
+		if c.sketch == nil {
+			c.docFreq[hashed] += count
+		}
+	}
+	c.unshare()
+	c.CatCount[category] += documents
+	c.warm = nil
+}
+
+// Reset forgets everything the model was trained on, keeping the tokenizer
+// and every setting and option, so the classifier can be retrained in place
+func (c *Classifier) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Feat2cat = make(map[string]map[string]float64)
+	c.CatCount = make(map[string]float64)
+	if c.sketch != nil {
+		c.sketch = newCountMinSketch(c.sketch.width, len(c.sketch.table))
+	}
+	c.examples = nil
+	c.docFreq = nil
+	c.reindex()
+}
+
+// RemoveCategory deletes the category and all of its word counts from the
+// model, dropping words that no longer occur in any other category. Other
+// categories are left untouched. Count-min sketch models cannot remove
+// categories.
+func (c *Classifier) RemoveCategory(category string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.CatCount[category]; !ok {
+		return fmt.Errorf("category not found: %s", category)
+	}
+	if c.sketch != nil {
+		return errors.New("count-min sketch models cannot remove categories")
+	}
+
+	for word, counts := range c.Feat2cat {
+		if count, ok := counts[category]; ok {
+			// the category's documents contain the word at most count times
+			c.removeDocumentWord(word, math.Min(count, c.CatCount[category]))
+			c.removeWordCount(word, category, count)
+		}
+	}
+	c.unshare()
+	delete(c.CatCount, category)
+	delete(c.catTokens, category)
+	if c.recency != nil {
+		c.recency.limitDocs(category, 0)
+	}
+	c.warm = nil
+	if c.retain {
+		examples := c.examples[:0]
+		for _, example := range c.examples {
+			if example.Category != category {
+				examples = append(examples, example)
+			}
+		}
+		c.examples = examples
+	}
+	return nil
+}
+
+// Classify returns the top category for the document read from r, or an
+// empty string when no category matches
+func (c *Classifier) Classify(r io.Reader) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.countOfAllResults() < float64(c.minDocs) {
+		return "", ErrInsufficientTraining
+	}
+	if c.streaming() {
+		_, topCategory, _ := c.classifyStream(c.Tokenizer.Tokenize(r))
+		return topCategory, nil
+	}
+	_, topCategory := c.probabilities(c.inputFeatures(c.Tokenizer.Tokenize(r)))
+	return topCategory, nil
+}
+
+// ClassifyString returns the top category for the provided string, or an
+// empty string when no category matches
+func (c *Classifier) ClassifyString(s string) (string, error) {
+	return c.Classify(AsReader(s))
+}
+
+// Predict returns the top category for the provided string, or an empty
+// string when no category matches
+func (c *Classifier) Predict(s string) (string, error) {
+	c.mu.RLock()
+	if c.countOfAllResults() < float64(c.minDocs) {
+		c.mu.RUnlock()
+		return "", ErrInsufficientTraining
+	}
+	var posteriors map[string]float64
+	var topCategory string
+	if c.streaming() {
+		posteriors, topCategory, _ = c.classifyStream(c.Tokenizer.Tokenize(AsReader(s)))
+	} else {
+		posteriors, topCategory = c.posteriors(c.features(s))
+	}
+	c.mu.RUnlock()
+
+	c.notify(s, posteriors, topCategory)
+	return topCategory, nil
+}
+
+// ClassifyEncoded transcodes the document read from r from the named
+// character encoding, e.g. "windows-1252" or "iso-8859-1", to UTF-8 before
+// classifying it. Encoding names follow the WHATWG Encoding Standard.
+func (c *Classifier) ClassifyEncoded(r io.Reader, encoding string) (string, error) {
+	enc, err := htmlindex.Get(encoding)
+	if err != nil {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	return c.Classify(transform.NewReader(r, enc.NewDecoder()))
+}
+
 // Probabilities runs the provided string through the model and returns
-// the potential probabilityForCategory for each classification
+// the potential probability for each classification. Scores are computed in
+// log space, so the top category is reliable even when the probabilities of
+// a long document underflow to zero and are left out; configure
+// WithNormalizedProbabilities to receive normalized values instead.
 func (c *Classifier) Probabilities(stringToClassify string) (map[string]float64, string) {
-	probabilities := make(map[string]float64)
+	probabilities, topCategory, _ := c.ProbabilitiesContext(context.Background(), stringToClassify)
+	return probabilities, topCategory
+}
+
+// ProbabilitiesContext behaves like Probabilities but stops scoring as soon as
+// ctx is done, returning ctx.Err(). The scoring goroutines check ctx before
+// every category, so a cancelled classification stops using CPU promptly.
+func (c *Classifier) ProbabilitiesContext(ctx context.Context, stringToClassify string) (map[string]float64, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	c.mu.RLock()
+	scores, topCategory, err := c.logScoresContext(ctx, c.features(stringToClassify), c.getAllCategories())
+	if err != nil {
+		c.mu.RUnlock()
+		return nil, "", err
+	}
+	probabilities := c.linear(scores)
+	c.mu.RUnlock()
+
+	if c.histogram == nil && c.hook == nil {
+		return probabilities, topCategory, nil
+	}
+	posteriors := softmax(scores)
+	if c.histogram != nil && topCategory != "" {
+		c.observeConfidence(posteriors[topCategory])
+	}
+	c.notify(stringToClassify, posteriors, topCategory)
+	return probabilities, topCategory, nil
+}
+
+// notify passes a classification to the configured hook, if any; callers
+// must not hold the lock so the hook may safely call back into the classifier
+func (c *Classifier) notify(input string, posteriors map[string]float64, label string) {
+	if c.hook == nil {
+		return
+	}
+	c.hook(input, label, posteriors[label])
+}
+
+// ProbabilitiesFor behaves like Probabilities but only scores the given
+// categories; categories unknown to the model are ignored
+func (c *Classifier) ProbabilitiesFor(s string, categories []string) (map[string]float64, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var known []string
+	for _, category := range categories {
+		if _, ok := c.CatCount[category]; ok {
+			known = append(known, category)
+		}
+	}
+	return c.probabilitiesFor(c.features(s), known)
+}
+
+// ProbabilitiesNormalized behaves like Probabilities but rescales the scores
+// so they sum to 1 across categories. The rescaling uses the log-sum-exp
+// trick on the log-scores, so it stays accurate for long documents whose
+// probabilities underflow, and categories that cannot score at all are left
+// out. When a display floor is configured every known category is included
+// and raised to at least the floor before renormalizing, so none is shown as
+// exactly zero.
+func (c *Classifier) ProbabilitiesNormalized(s string) (map[string]float64, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	normalized, topCategory := c.posteriors(c.features(s))
+	if c.display <= 0 || len(normalized) == 0 {
+		return normalized, topCategory
+	}
+
+	for _, category := range c.getAllCategories() {
+		normalized[category] = math.Max(normalized[category], c.display)
+	}
+	return normalize(normalized), topCategory
+}
+
+// PosteriorMatrix scores every input and returns the model's categories in
+// sorted order along with one row per input, where matrix[i][j] is the
+// normalized posterior of categories[j] for inputs[i]. Rows of inputs no
+// category matches are all zeros. The matrix is suitable as features for a
+// downstream model.
+func (c *Classifier) PosteriorMatrix(inputs []string) (categories []string, matrix [][]float64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories = c.sortedCategories()
+	matrix = make([][]float64, len(inputs))
+	for i, input := range inputs {
+		posteriors, _ := c.posteriors(c.features(input))
+		matrix[i] = make([]float64, len(categories))
+		for j, category := range categories {
+			matrix[i][j] = posteriors[category]
+		}
+	}
+	return categories, matrix
+}
+
+// DominatedCategories classifies every sample input and returns, sorted, the
+// categories that never came out on top. This is a heuristic for finding
+// under-trained or feature-poor categories that are candidates for pruning;
+// its verdict is only as good as the samples are representative.
+func (c *Classifier) DominatedCategories(sampleInputs []string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	won := make(map[string]bool)
+	for _, input := range sampleInputs {
+		_, topCategory := c.logScoresFor(c.features(input), c.getAllCategories())
+		won[topCategory] = true
+	}
+
+	var dominated []string
+	for _, category := range c.sortedCategories() {
+		if !won[category] {
+			dominated = append(dominated, category)
+		}
+	}
+	return dominated
+}
+
+// ProbabilitiesIgnoring behaves like Probabilities but drops the ignored
+// words from the input's features before scoring. The ignored words are run
+// through the classifier's tokenizer so they match the stored feature form.
+// The model itself is left untouched.
+func (c *Classifier) ProbabilitiesIgnoring(s string, ignore []string) (map[string]float64, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	skip := make(map[string]bool)
+	for _, word := range ignore {
+		for feature := range c.Tokenizer.Tokenize(AsReader(word)) {
+			skip[c.hashWord(feature)] = true
+		}
+	}
+
+	var features []feature
+	for _, feature := range c.features(s) {
+		if !skip[feature.word] {
+			features = append(features, feature)
+		}
+	}
+
+	return c.probabilities(features)
+}
+
+// ClassifyExplained returns the top category for the provided string along
+// with whether any of its tokens were known to the model. Unknown tokens are
+// left out of the scoring, so when usedVocabulary is false the label was
+// decided by the category priors alone and should be distrusted.
+func (c *Classifier) ClassifyExplained(s string) (label string, usedVocabulary bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var known []feature
+	for _, feature := range c.features(s) {
+		if c.wordCount(feature.word) > 0 {
+			known = append(known, feature)
+		}
+	}
+
+	_, label = c.probabilities(known)
+	return label, len(known) > 0
+}
+
+// ClassifyWithMinTokens returns the top category for the provided string, or
+// ("", false) when fewer than minKnown of its tokens are known to the model,
+// so a single stray keyword is not trusted. As with ClassifyExplained only
+// the known tokens are scored.
+func (c *Classifier) ClassifyWithMinTokens(s string, minKnown int) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var known []feature
+	for _, feature := range c.features(s) {
+		if c.wordCount(feature.word) > 0 {
+			known = append(known, feature)
+		}
+	}
+	if len(known) == 0 || len(known) < minKnown {
+		return "", false
+	}
+
+	_, label := c.probabilities(known)
+	return label, label != ""
+}
+
+// negligibleEvidence is the log-likelihood ratio, in nats, below which a
+// document's words are considered to say nothing about a category
+const negligibleEvidence = 1e-6
+
+// ClassifyOrReject returns the top category for the provided string, or ("",
+// false) when no category fits: either none of its tokens are known to the
+// model or no category's word-likelihood term rises above the background
+// expectation, so the decision would rest on the priors alone. As with
+// ClassifyExplained only the known tokens are scored.
+func (c *Classifier) ClassifyOrReject(s string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var known []feature
+	for _, feature := range c.features(s) {
+		if c.wordCount(feature.word) > 0 {
+			known = append(known, feature)
+		}
+	}
+	if len(known) == 0 {
+		return "", false
+	}
+
+	evidence := math.Inf(-1)
+	for _, score := range c.logScores(known, false) {
+		evidence = math.Max(evidence, score)
+	}
+	if evidence <= negligibleEvidence {
+		return "", false
+	}
+
+	_, label := c.probabilities(known)
+	return label, label != ""
+}
+
+// ClassifyWithThreshold returns the top category for the provided string
+// along with its normalized posterior probability, its confidence. When the
+// confidence is below minConfidence the fallback category configured with
+// WithFallbackCategory, "" by default, is returned instead, still along with
+// the confidence of the top category so it can be logged.
+func (c *Classifier) ClassifyWithThreshold(s string, minConfidence float64) (string, float64, error) {
+	if !(minConfidence >= 0 && minConfidence <= 1) {
+		return "", 0, fmt.Errorf("invalid confidence threshold: %v", minConfidence)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.countOfAllResults() < float64(c.minDocs) {
+		return "", 0, ErrInsufficientTraining
+	}
+	var posteriors map[string]float64
+	var topCategory string
+	if c.streaming() {
+		posteriors, topCategory, _ = c.classifyStream(c.Tokenizer.Tokenize(AsReader(s)))
+	} else {
+		posteriors, topCategory = c.posteriors(c.features(s))
+	}
+
+	confidence := posteriors[topCategory]
+	if topCategory == "" || confidence < minConfidence {
+		return c.fallback, confidence, nil
+	}
+	return topCategory, confidence, nil
+}
+
+// ClassifyWithCosts returns the category with the lowest expected
+// misclassification cost rather than the highest probability. costs[actual]
+// [predicted] is the cost of predicting the second category when the first is
+// correct; missing entries default to 0/1 loss (0 for a correct prediction, 1
+// otherwise). The expectation is taken over the normalized posteriors.
+func (c *Classifier) ClassifyWithCosts(s string, costs map[string]map[string]float64) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	posteriors, _ := c.posteriors(c.features(s))
+
+	cost := func(actual, predicted string) float64 {
+		if v, ok := costs[actual][predicted]; ok {
+			return v
+		}
+		if actual == predicted {
+			return 0
+		}
+		return 1
+	}
+
+	best := ""
+	bestCost := math.Inf(1)
+	for _, predicted := range c.sortedCategories() {
+		expected := 0.0
+		for actual, p := range posteriors {
+			expected += p * cost(actual, predicted)
+		}
+		if expected < bestCost {
+			best, bestCost = predicted, expected
+		}
+	}
+
+	return best
+}
+
+// PredictionEntropy returns the Shannon entropy, in nats, of the normalized
+// posterior over categories for the provided string. Values near zero mean
+// the model is confident while values near log(number of categories) flag an
+// uncertain input. It returns 0 when no category scores above zero, such as
+// for an untrained classifier.
+func (c *Classifier) PredictionEntropy(s string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	posteriors, _ := c.posteriors(c.features(s))
+
+	entropy := 0.0
+	for _, p := range posteriors {
+		if p > 0 {
+			entropy -= p * math.Log(p)
+		}
+	}
+	return entropy
+}
+
+// ConfidenceLift returns the top category for the provided string along with
+// its normalized probability divided by the 1/n probability of a uniformly
+// random guess among the n categories. A lift near 1 means the model adds
+// nothing over guessing for this input while n means complete certainty. It
+// returns ("", 0) when no category matches.
+func (c *Classifier) ConfidenceLift(s string) (label string, lift float64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	posteriors, label := c.posteriors(c.features(s))
+	if label == "" {
+		return "", 0
+	}
+	return label, posteriors[label] * float64(len(c.CatCount))
+}
+
+// BackgroundProbability returns the frequency of the word across the whole
+// model, i.e. its count summed over every category divided by the total
+// number of stored tokens. It is derived from the per-category counts rather
+// than stored separately and returns 0 for an empty model.
+func (c *Classifier) BackgroundProbability(word string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	total := 0.0
+	for _, tokens := range c.catTokens {
+		total += tokens
+	}
+	if total == 0 {
+		return 0
+	}
+	return c.wordCount(c.hashWord(word)) / total
+}
+
+// TotalDocumentCount returns the number of documents the model was trained
+// on, counting weighted documents by their weight
+func (c *Classifier) TotalDocumentCount() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.countOfAllResults()
+}
+
+// Categories returns the sorted names of the categories the model was
+// trained on
+func (c *Classifier) Categories() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories := make([]string, 0, len(c.CatCount))
+	for category := range c.CatCount {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Priors returns the prior probability P(category) of every category, i.e.
+// its share of the training documents, or the same value for every category
+// when WithUniformPrior or WithoutPrior is configured, taking any overrides
+// set with SetPriors into account unless WithoutPrior is configured. The
+// priors sum to 1; an untrained classifier has none.
+func (c *Classifier) Priors() map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	totalCount := c.totalCount()
+	priors := make(map[string]float64, len(c.CatCount))
+	for category := range c.CatCount {
+		if c.prior == noPrior {
+			priors[category] = 1 / float64(len(c.CatCount))
+		} else {
+			priors[category] = c.probabilityOfCategory(category, totalCount)
+		}
+	}
+	return priors
+}
+
+// TokenCoverage returns, per category, the fraction of the provided string's
+// tokens that the category has seen at least once during training. A
+// category can outscore another merely by covering more of the input, which
+// this makes visible. It returns an empty map for an input without tokens.
+func (c *Classifier) TokenCoverage(s string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	features := c.features(s)
+	coverage := make(map[string]float64)
+	if len(features) == 0 {
+		return coverage
+	}
+
+	for _, category := range c.getAllCategories() {
+		covered := 0
+		for _, feature := range features {
+			if c.countOfWordInCategory(feature.word, category) > 0 {
+				covered++
+			}
+		}
+		coverage[category] = float64(covered) / float64(len(features))
+	}
+	return coverage
+}
+
+// ClassifyWithinGap returns the top category for the provided string followed
+// by every other category whose normalized probability is at least
+// (1-relativeGap) times the top one, in descending order. A gap of 0 only
+// returns categories tied with the top one.
+func (c *Classifier) ClassifyWithinGap(s string, relativeGap float64) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	posteriors, _ := c.posteriors(c.features(s))
+	ranked := rank(posteriors)
+	if len(ranked) == 0 {
+		return nil
+	}
+
+	cutoff := (1 - relativeGap) * ranked[0].Score
+	var categories []string
+	for _, score := range ranked {
+		if score.Score < cutoff {
+			break
+		}
+		categories = append(categories, score.Label)
+	}
+	return categories
+}
+
+// ClassifyMulti returns every category whose normalized probability for the
+// provided string exceeds minScore, in descending order with ties broken by
+// category, for documents that belong to several categories at once. Since
+// the probabilities sum to 1, at most one category can exceed 0.5. It returns
+// an empty slice when no category clears minScore.
+func (c *Classifier) ClassifyMulti(s string, minScore float64) ([]string, error) {
+	if !(minScore >= 0 && minScore <= 1) {
+		return nil, fmt.Errorf("invalid minimum score: %v", minScore)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.countOfAllResults() < float64(c.minDocs) {
+		return nil, ErrInsufficientTraining
+	}
+	posteriors, _ := c.posteriors(c.features(s))
+	categories := []string{}
+	for _, score := range rank(posteriors) {
+		if score.Score <= minScore {
+			break
+		}
+		categories = append(categories, score.Label)
+	}
+	return categories, nil
+}
+
+// Result is a category ranked by its normalized probability
+type Result struct {
+	Category string
+	Score    float64
+}
 
+// TopN returns the n most probable categories for the provided string along
+// with their normalized probabilities, in descending order with ties broken
+// by category name. Fewer results are returned when the model knows fewer
+// than n categories.
+func (c *Classifier) TopN(stringToClassify string, n int) []Result {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	var features []string
-	for feature := range c.Tokenizer.Tokenize(AsReader(stringToClassify)) {
-		features = append(features, feature)
+	posteriors, _ := c.posteriors(c.features(stringToClassify))
+	ranked := rank(posteriors)
+	if n < 0 {
+		n = 0
+	}
+	if n < len(ranked) {
+		ranked = ranked[:n]
 	}
+	results := make([]Result, len(ranked))
+	for i, score := range ranked {
+		results[i] = Result{Category: score.Label, Score: score.Score}
+	}
+	return results
+}
+
+// ClassifyStats reports how the tokens of a classified document were used
+type ClassifyStats struct {
+	// Total is the number of tokens read from the document
+	Total int
+	// Known is the number of scored tokens present in the model
+	Known int
+	// Unknown is the number of scored tokens absent from the model
+	Unknown int
+	// Dropped is the number of tokens removed by the tokenizer's filters
+	Dropped int
+}
+
+// ClassifyWithStats returns the top category for the provided string along
+// with tokenizer coverage statistics. As with ClassifyExplained only the known
+// tokens are scored. Dropped tokens are only reported by
+// tokenizers implementing classifier.StatsTokenizer; for any other tokenizer
+// Total is the number of tokens it produced.
+func (c *Classifier) ClassifyWithStats(s string) (label string, stats ClassifyStats) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var tokens chan string
+	var tokenizerStats *classifier.Stats
+	if t, ok := c.Tokenizer.(classifier.StatsTokenizer); ok {
+		tokens, tokenizerStats = t.TokenizeStats(AsReader(s))
+	} else {
+		tokens = c.Tokenizer.Tokenize(AsReader(s))
+	}
+
+	var known []feature
+	for _, feature := range c.inputFeatures(tokens) {
+		if c.wordCount(feature.word) > 0 {
+			known = append(known, feature)
+		} else {
+			stats.Unknown++
+		}
+	}
+
+	stats.Known = len(known)
+	stats.Total = stats.Known + stats.Unknown
+	if tokenizerStats != nil {
+		stats.Total = tokenizerStats.Read
+		stats.Dropped = tokenizerStats.Dropped
+	}
+
+	_, label = c.probabilities(known)
+	return label, stats
+}
+
+// DebugTokens returns the tokens the classifier's own tokenizer produces for
+// the provided string, in order and with repetitions, before they are turned
+// into features by n-gram mixing, hashing or deduplication
+func (c *Classifier) DebugTokens(s string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-	totalCount := c.countOfAllResults()
-	categories := c.getAllCategories()
-	numberOfGroups := 1
+	var tokens []string
+	for token := range c.Tokenizer.Tokenize(AsReader(s)) {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// feature is a scored word along with the weight its contribution is given
+type feature struct {
+	word   string
+	weight float64
+}
+
+// LogScores returns, per category, the log of the score Probabilities would
+// report: log P(category) plus the summed log ratios between each word's
+// probability within the category and its overall probability. Categories
+// scoring zero are omitted.
+func (c *Classifier) LogScores(s string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.logScores(c.features(s), true)
+}
+
+// LikelihoodScores behaves like LogScores but leaves out the log P(category)
+// prior, returning the document likelihood term alone so callers can apply
+// priors of their own
+func (c *Classifier) LikelihoodScores(s string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.logScores(c.features(s), false)
+}
+
+// Softmax returns the softmax over the per-category log-scores reported by
+// LogScores divided by temperature. A temperature of 1 matches the
+// normalized posteriors, higher temperatures flatten the distribution and
+// lower ones sharpen it. The values sum to 1 unless no category scored. It
+// panics if temperature is not positive.
+func (c *Classifier) Softmax(s string, temperature float64) map[string]float64 {
+	if !(temperature > 0) {
+		panic(fmt.Sprintf("invalid temperature: %v", temperature))
+	}
+
+	scores := c.LogScores(s)
+	categories := make([]string, 0, len(scores))
+	for category, score := range scores {
+		categories = append(categories, category)
+		scores[category] = score / temperature
+	}
+	sort.Strings(categories)
+
+	posteriors, _ := softmaxScores(categories, scores)
+	return posteriors
+}
+
+// logScores computes the per-category log-scores, optionally including the
+// prior; callers must hold the read lock
+func (c *Classifier) logScores(features []feature, prior bool) map[string]float64 {
+	totalCount := c.totalCount()
+	background := c.wordProbabilities(features, totalCount)
+	scores := make(map[string]float64)
+	for _, category := range c.getAllCategories() {
+		score := c.logProbabilityForCategory(features, category, background, totalCount, prior && c.prior != noPrior)
+		if !math.IsNaN(score) && !math.IsInf(score, 0) {
+			scores[category] = score
+		}
+	}
+	return scores
+}
+
+// features tokenizes the provided string into the features used for scoring
+func (c *Classifier) features(s string) []feature {
+	return c.inputFeatures(c.Tokenizer.Tokenize(AsReader(s)))
+}
+
+// inputFeatures turns the tokens of a document being classified into
+// features, reducing them to a set when unique input tokens are configured
+func (c *Classifier) inputFeatures(tokens chan string) []feature {
+	features := c.featuresOf(tokens)
+	if c.unique {
+		seen := make(map[string]bool, len(features))
+		unique := features[:0]
+		for _, feature := range features {
+			if !seen[feature.word] {
+				seen[feature.word] = true
+				unique = append(unique, feature)
+			}
+		}
+		features = unique
+	}
+	if c.tfidf {
+		for i := range features {
+			features[i].weight *= c.idf(features[i].word)
+		}
+	}
+	return features
+}
+
+// featuresOf turns a stream of tokens into features, expanding them into
+// n-grams when a mixture is configured and weighting them by position when a
+// positional decay is configured. Weights only affect scoring.
+func (c *Classifier) featuresOf(tokens chan string) []feature {
+	var words []string
+	for token := range tokens {
+		words = append(words, c.hashWord(token))
+	}
+
+	position := func(i int) float64 {
+		if c.decay == 0 || c.decay == 1 {
+			return 1
+		}
+		return math.Pow(c.decay, float64(i))
+	}
+
+	if c.ngrams == nil {
+		features := make([]feature, len(words))
+		for i, word := range words {
+			features[i] = feature{word: word, weight: position(i)}
+		}
+		return features
+	}
+
+	orders := make([]int, 0, len(c.ngrams))
+	for n := range c.ngrams {
+		orders = append(orders, n)
+	}
+	sort.Ints(orders)
+
+	var features []feature
+	for _, n := range orders {
+		for i := 0; i+n <= len(words); i++ {
+			features = append(features, feature{word: strings.Join(words[i:i+n], ngramSeparator), weight: c.ngrams[n] * position(i)})
+		}
+	}
+	return features
+}
+
+// hashWord replaces the word with its salted hash when feature hashing is
+// configured
+func (c *Classifier) hashWord(word string) string {
+	if c.salt == nil {
+		return word
+	}
+	sum := sha256.Sum256([]byte(*c.salt + "\x00" + word))
+	return hex.EncodeToString(sum[:16])
+}
+
+// probabilities scores the features against every category; callers must
+// hold the read lock
+func (c *Classifier) probabilities(features []feature) (map[string]float64, string) {
+	return c.probabilitiesFor(features, c.getAllCategories())
+}
+
+// posteriors scores the features against every category and returns the
+// normalized posteriors along with the top category; callers must hold the
+// read lock
+func (c *Classifier) posteriors(features []feature) (map[string]float64, string) {
+	scores, topCategory := c.logScoresFor(features, c.getAllCategories())
+	return softmax(scores), topCategory
+}
+
+// probabilitiesFor scores the features against the given categories; callers
+// must hold the read lock
+func (c *Classifier) probabilitiesFor(features []feature, categories []string) (map[string]float64, string) {
+	scores, topCategory := c.logScoresFor(features, categories)
+	return c.linear(scores), topCategory
+}
+
+// linear turns log-scores into the values reported by Probabilities
+func (c *Classifier) linear(scores map[string]float64) map[string]float64 {
+	if c.normalized {
+		return softmax(scores)
+	}
+
+	// exponentiating may underflow to zero for long documents, leaving only
+	// the top category to report
+	probabilities := make(map[string]float64, len(scores))
+	for category, score := range scores {
+		if probability := math.Exp(score); probability > 0 {
+			probabilities[category] = probability
+		}
+	}
+	return probabilities
+}
+
+// logScoresFor computes the log-scores of the features against the given
+// categories, leaving out categories that cannot score, and returns them
+// along with the top category; callers must hold the read lock
+func (c *Classifier) logScoresFor(features []feature, categories []string) (map[string]float64, string) {
+	scores, topCategory, _ := c.logScoresContext(context.Background(), features, categories)
+	return scores, topCategory
+}
+
+// logScoresContext behaves like logScoresFor but abandons scoring with
+// ctx.Err() once ctx is done
+func (c *Classifier) logScoresContext(ctx context.Context, features []feature, categories []string) (map[string]float64, string, error) {
+	start := time.Now()
+	scores := make(map[string]float64)
+
+	totalCount := c.totalCount()
+	numberOfGroups := c.Concurrency
+	if numberOfGroups > len(categories) {
+		numberOfGroups = len(categories)
+	}
+	if numberOfGroups < 1 {
+		numberOfGroups = 1
+	}
 	groupSize := int(math.Ceil(float64(len(categories)) / float64(numberOfGroups)))
 
 	var lock sync.Mutex
 	var wg sync.WaitGroup
 	wg.Add(numberOfGroups)
 
+	background := c.wordProbabilities(features, totalCount)
 	for i := 0; i < numberOfGroups; i++ {
-		go probabilityGrouped(c, categories, features, probabilities, float64(totalCount), &wg, i, groupSize, lock)
+		go probabilityGrouped(ctx, c, categories, features, background, scores, totalCount, &wg, i*groupSize, groupSize, &lock)
 	}
 
-	fmt.Println("Calculating probabilities...")
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
 
-	keys := make([]string, 0, len(probabilities))
-	for category := range probabilities {
+	keys := make([]string, 0, len(scores))
+	for category := range scores {
 		keys = append(keys, category)
 	}
 
-	sort.SliceStable(keys, func(i, j int) bool {
-		return probabilities[keys[i]] > probabilities[keys[j]]
+	// log is monotonic, so ranking the log-scores ranks the probabilities;
+	// ties are broken by category so the result never depends on scheduling
+	sort.Slice(keys, func(i, j int) bool {
+		if scores[keys[i]] != scores[keys[j]] {
+			return scores[keys[i]] > scores[keys[j]]
+		}
+		return keys[i] < keys[j]
 	})
 
 	topCategory := ""
@@ -91,38 +1214,157 @@ func (c *Classifier) Probabilities(stringToClassify string) (map[string]float64,
 		topCategory = keys[0]
 	}
 
-	return probabilities, topCategory
+	if c.Logger != nil {
+		for _, category := range keys {
+			c.Logger.Printf("naive: category %s scored %g", category, scores[category])
+		}
+		c.Logger.Printf("naive: scored %d features against %d categories in %s", len(features), len(categories), time.Since(start))
+	}
+
+	return scores, topCategory, nil
 }
 
-func probabilityGrouped(c *Classifier, categories []string, words []string, probabilities map[string]float64, totalCount float64, wg *sync.WaitGroup, offset int, groupSize int, lock sync.Mutex) {
+func probabilityGrouped(ctx context.Context, c *Classifier, categories []string, words []feature, background map[string]float64, scores map[string]float64, totalCount float64, wg *sync.WaitGroup, offset int, groupSize int, lock *sync.Mutex) {
 	defer wg.Done()
-	probabilitiesForThisGroup := map[string]float64{}
+	scoresForThisGroup := map[string]float64{}
 	for i := offset; i < offset+groupSize; i++ {
+		if ctx.Err() != nil {
+			return
+		}
 		if i < len(categories) {
-			probability := c.probabilityForCategory(words, categories[i], totalCount)
-			if probability > 0 {
-				probabilitiesForThisGroup[categories[i]] = probability
+			score := c.logProbabilityForCategory(words, categories[i], background, totalCount, c.prior != noPrior)
+			if !math.IsNaN(score) && !math.IsInf(score, 0) {
+				scoresForThisGroup[categories[i]] = score
 			}
 		}
 	}
 
 	lock.Lock()
-	for key, value := range probabilitiesForThisGroup {
-		probabilities[key] = value
+	for key, value := range scoresForThisGroup {
+		scores[key] = value
 	}
 	lock.Unlock()
 }
 
 func (c *Classifier) addWord(word string, category string) {
+	c.addWordCount(word, category, 1)
+}
+
+// addWordCount records n occurrences of the word in the category
+func (c *Classifier) addWordCount(word string, category string, n float64) {
+	c.catTokens[category] += n
+	if c.sketch != nil {
+		c.sketch.add(word, category, n)
+		return
+	}
+	c.ownWord(word)
 	if _, ok := c.Feat2cat[word]; !ok {
-		c.Feat2cat[word] = make(map[string]int)
+		c.Feat2cat[word] = make(map[string]float64)
+		c.foldWord(word)
+	}
+	c.Feat2cat[word][category] += n
+}
+
+// removeWordCount removes n occurrences of the word from the category,
+// dropping the word once it no longer occurs in any category
+func (c *Classifier) removeWordCount(word string, category string, n float64) {
+	c.catTokens[category] -= n
+	if c.catTokens[category] == 0 {
+		delete(c.catTokens, category)
+	}
+	c.ownWord(word)
+	c.Feat2cat[word][category] -= n
+	if c.Feat2cat[word][category] == 0 {
+		delete(c.Feat2cat[word], category)
+	}
+	if len(c.Feat2cat[word]) == 0 {
+		c.removeWord(word)
+		return
+	}
+	if c.recency != nil {
+		c.recency.limitWord(word, category, c.Feat2cat[word][category])
+	}
+}
+
+// addDocument counts a document of the given weight towards the document
+// frequency of each distinct feature; callers must hold the write lock
+func (c *Classifier) addDocument(features []feature, weight float64) {
+	if c.sketch != nil {
+		return
+	}
+	c.unshare()
+	seen := make(map[string]bool, len(features))
+	for _, feature := range features {
+		if !seen[feature.word] {
+			seen[feature.word] = true
+			c.docFreq[feature.word] += weight
+		}
+	}
+}
+
+// removeDocumentWord removes n documents from the document frequency of the
+// word; callers must hold the write lock
+func (c *Classifier) removeDocumentWord(word string, n float64) {
+	c.unshare()
+	if c.docFreq[word] <= n {
+		delete(c.docFreq, word)
+		return
+	}
+	c.docFreq[word] -= n
+}
+
+// foldWord indexes the word under its lowercased form for case fallback
+func (c *Classifier) foldWord(word string) {
+	if c.folded == nil {
+		return
+	}
+	key := strings.ToLower(word)
+	if c.folded[key] == nil {
+		c.folded[key] = make(map[string]bool)
+	}
+	c.folded[key][word] = true
+}
+
+// reindex rebuilds the state derived from Feat2cat after it was replaced;
+// callers must hold the write lock
+func (c *Classifier) reindex() {
+	c.warm = nil
+	c.shared = false
+	c.owned = nil
+	if c.recency != nil {
+		c.recency.reset()
+	}
+	c.catTokens = countTokens(c.Feat2cat)
+	if c.docFreq == nil {
+		c.docFreq = estimateDocFreq(c.Feat2cat, c.countOfAllResults())
+	}
+	if c.folded != nil {
+		c.folded = make(map[string]map[string]bool)
+		for word := range c.Feat2cat {
+			c.foldWord(word)
+		}
 	}
-	c.Feat2cat[word][category]++
 }
 
 func (c *Classifier) countOfWordInCategory(word string, category string) float64 {
+	if c.sketch != nil {
+		return c.sketch.estimate(word, category)
+	}
+	if c.mapped != nil {
+		return c.mapped.count(word, category)
+	}
 	if _, ok := c.Feat2cat[word]; ok {
-		return float64(c.Feat2cat[word][category])
+		if c.recency != nil {
+			return c.Feat2cat[word][category] + c.recency.wordAdjustment(word, category)
+		}
+		return c.Feat2cat[word][category]
+	}
+	if c.folded != nil {
+		sum := 0.0
+		for variant := range c.folded[strings.ToLower(word)] {
+			sum += c.Feat2cat[variant][category]
+		}
+		return sum
 	}
 	return 0.0
 }
@@ -130,20 +1372,63 @@ func (c *Classifier) countOfWordInCategory(word string, category string) float64
 // p (category)
 func (c *Classifier) totalCountInCategory(category string) float64 {
 	if _, ok := c.CatCount[category]; ok {
-		return float64(c.CatCount[category])
+		if c.recency != nil {
+			return c.CatCount[category] + c.recency.docAdjustment(category)
+		}
+		return c.CatCount[category]
 	}
 	return 0.0
 }
 
-func (c *Classifier) countOfAllResults() int {
-	sum := 0
+// totalCount returns the number of training documents used for scoring,
+// which is decayed by age when a half-life is configured
+func (c *Classifier) totalCount() float64 {
+	total := c.countOfAllResults()
+	if c.recency != nil {
+		for category := range c.recency.rawDocs {
+			total += c.recency.docAdjustment(category)
+		}
+	}
+	return total
+}
+
+func (c *Classifier) countOfAllResults() float64 {
+	if c.warm != nil {
+		return c.warm.total
+	}
+	sum := 0.0
 	for _, value := range c.CatCount {
 		sum += value
 	}
 	return sum
 }
 
+// estimateDocFreq approximates the document frequencies of a model saved
+// without them by the total count of each word, which is exact unless a
+// document contained the word more than once, capped at the documents total
+func estimateDocFreq(feat2cat map[string]map[string]float64, documents float64) map[string]float64 {
+	docFreq := make(map[string]float64, len(feat2cat))
+	for word, counts := range feat2cat {
+		docFreq[word] = math.Min(sumCounts(counts), documents)
+	}
+	return docFreq
+}
+
+// countTokens derives the number of stored tokens per category
+func countTokens(feat2cat map[string]map[string]float64) map[string]float64 {
+	tokens := make(map[string]float64)
+	for _, counts := range feat2cat {
+		for category, count := range counts {
+			tokens[category] += count
+		}
+	}
+	return tokens
+}
+
 func (c *Classifier) getAllCategories() []string {
+	if c.warm != nil {
+		return c.warm.categories
+	}
 	var keys []string
 	for k := range c.CatCount {
 		keys = append(keys, k)
@@ -154,55 +1439,242 @@ func (c *Classifier) getAllCategories() []string {
 func (c *Classifier) probabilityOfWordInCategory(word string, category string) float64 {
 	totalCountInCategory := c.totalCountInCategory(category)
 	countOfWordInCategory := c.countOfWordInCategory(word, category)
+	if vocabularySize := float64(c.vocabularySize()); c.adaptive && vocabularySize > 0 {
+		alpha := c.adaptiveAlpha(category, vocabularySize)
+		return (countOfWordInCategory + alpha) / (totalCountInCategory + alpha*vocabularySize)
+	}
+	if vocabularySize := float64(c.vocabularySize()); c.Alpha > 0 && vocabularySize > 0 {
+		return (countOfWordInCategory + c.Alpha) / (totalCountInCategory + c.Alpha*vocabularySize)
+	}
 	probability := countOfWordInCategory / totalCountInCategory
+	if probability == 0 && c.floor > 0 {
+		return c.floor
+	}
 	return probability
 }
 
+// adaptiveAlpha returns the smoothing strength of a category, which shrinks
+// as the category accumulates tokens relative to the vocabulary size
+func (c *Classifier) adaptiveAlpha(category string, vocabularySize float64) float64 {
+	return vocabularySize / (vocabularySize + c.catTokens[category])
+}
+
+func (c *Classifier) vocabularySize() int {
+	if c.sketch != nil {
+		return int(math.Round(c.sketch.vocabulary()))
+	}
+	if c.mapped != nil {
+		return c.mapped.numWords
+	}
+	return len(c.Feat2cat)
+}
+
 func (c *Classifier) probabilityOfWordInTotalWords(word string, totalCount float64) float64 {
+	if vocabularySize := float64(c.vocabularySize()); c.Alpha > 0 && vocabularySize > 0 {
+		// smoothed like the per-category probabilities so the ratio stays
+		// finite for a word unknown to the model. The background is shared
+		// by every category and leaves the ranking unchanged; the bias of an
+		// unknown word towards small categories comes from its per-category
+		// probability, see Alpha.
+		return (c.wordCount(word) + c.Alpha) / (totalCount + c.Alpha*vocabularySize)
+	}
 	return c.wordCount(word) / totalCount
 }
 
-func (c *Classifier) probabilityForCategory(words []string, category string, totalCount float64) float64 {
-	//fmt.Println("")
-	//fmt.Println("Category: ", category)
-	wordProbability := c.probabilityOfEachWordForCategory(words, category, totalCount)
-	categoryProbability := c.probabilityOfCategory(category, totalCount)
-	probability := wordProbability * categoryProbability
+// wordProbabilities computes P(word) once for every distinct word so scoring
+// does not re-sum a word's counts for each category
+func (c *Classifier) wordProbabilities(words []feature, totalCount float64) map[string]float64 {
+	background := make(map[string]float64, len(words))
+	for _, word := range words {
+		if _, ok := background[word.word]; !ok {
+			background[word.word] = c.probabilityOfWordInTotalWords(word.word, totalCount)
+		}
+	}
+	return background
+}
 
-	//fmt.Println("Category probability: ", categoryProbability)
-	//fmt.Println("Probability: ", probability)
-	return probability
+// logProbabilityForCategory returns log p(category) + log p(document |
+// category), leaving out the prior when requested. Summing logs rather than
+// multiplying probabilities keeps long documents from underflowing to zero.
+func (c *Classifier) logProbabilityForCategory(words []feature, category string, background map[string]float64, totalCount float64, prior bool) float64 {
+	if len(words) > 0 && c.catTokens[category] == 0 {
+		// a category that never saw a single feature has no word evidence to
+		// offer and can only be chosen on its prior for an empty input
+		return math.Inf(-1)
+	}
+	logProbability := c.logProbabilityOfEachWordForCategory(words, category, background)
+	if !prior || c.complement {
+		return logProbability
+	}
+	return logProbability + math.Log(c.probabilityOfCategory(category, totalCount))
 }
 
 func (c *Classifier) wordCount(word string) float64 {
+	if c.sketch != nil {
+		sum := 0.0
+		for category := range c.CatCount {
+			sum += c.countOfWordInCategory(word, category)
+		}
+		return sum
+	}
+	if c.mapped != nil {
+		return c.mapped.total(word)
+	}
 	if _, ok := c.Feat2cat[word]; ok {
-		sum := 0
-		for _, count := range c.Feat2cat[word] {
-			sum += count
+		if c.recency != nil {
+			sum := 0.0
+			for category := range c.Feat2cat[word] {
+				sum += c.countOfWordInCategory(word, category)
+			}
+			return sum
 		}
-		return float64(sum)
+		return sumCounts(c.Feat2cat[word])
+	}
+	if c.folded != nil {
+		sum := 0.0
+		for variant := range c.folded[strings.ToLower(word)] {
+			sum += sumCounts(c.Feat2cat[variant])
+		}
+		return sum
 	}
 	return 0.0
 }
 
-// p (document | category)
-func (c *Classifier) probabilityOfEachWordForCategory(words []string, category string, totalCount float64) float64 {
-	probability := 1.0
+// idf returns the inverse document frequency log(documents / (1 + n)) of the
+// word, where n is the number of training documents containing it. Words
+// found in nearly every document would get a negative weight, so the result
+// is clamped at zero. Words unknown to the model carry no frequency
+// information and get no weight either.
+func (c *Classifier) idf(word string) float64 {
+	if c.wordCount(word) == 0 {
+		return 0
+	}
+	return math.Max(0, math.Log(c.countOfAllResults()/(1+c.documentFrequency(word))))
+}
+
+// documentFrequency returns the number of training documents containing the
+// word. Count-min sketch and memory-mapped models do not record it, so the
+// word's total count stands in for it.
+func (c *Classifier) documentFrequency(word string) float64 {
+	if c.sketch != nil || c.mapped != nil {
+		return c.wordCount(word)
+	}
+	if n, ok := c.docFreq[word]; ok {
+		return n
+	}
+	sum := 0.0
+	if c.folded != nil {
+		for variant := range c.folded[strings.ToLower(word)] {
+			sum += c.docFreq[variant]
+		}
+	}
+	return sum
+}
+
+// log p (document | category)
+func (c *Classifier) logProbabilityOfEachWordForCategory(words []feature, category string, background map[string]float64) float64 {
+	logProbability := 0.0
 	for _, word := range words {
-		probabilityOfWordInCategory := c.probabilityOfWordInCategory(word, category)
-		probabilityOfWordInTotalWords := c.probabilityOfWordInTotalWords(word, totalCount)
-		//fmt.Println("Word in cat probability: ", probabilityOfWordInCategory)
-		//fmt.Println("Word probability: ", probabilityOfWordInTotalWords)
-		probability *= probabilityOfWordInCategory / probabilityOfWordInTotalWords
+		logProbability += c.logContribution(word, category, background)
 	}
-	return probability
+	return logProbability
+}
+
+// logContribution returns the term a single feature adds to the log-score of
+// the category
+func (c *Classifier) logContribution(word feature, category string, background map[string]float64) float64 {
+	if word.weight == 0 {
+		return 0
+	}
+	if c.unknown != SmoothUnknownWords && c.wordCount(word.word) == 0 {
+		if c.unknown == FloorUnknownWords {
+			return word.weight * math.Log(c.unknownFloor())
+		}
+		return 0
+	}
+	if c.complement {
+		if c.wordCount(word.word) == 0 {
+			// an unknown word says nothing about any complement
+			return 0
+		}
+		ratio := c.probabilityOfWordInComplement(word.word, category) / background[word.word]
+		return -word.weight * math.Log(ratio)
+	}
+	ratio := c.probabilityOfWordInCategory(word.word, category) / background[word.word]
+	return word.weight * math.Log(ratio)
+}
+
+// probabilityOfWordInComplement estimates p(word | not category) from the
+// counts of every other category, smoothed like probabilityOfWordInCategory
+func (c *Classifier) probabilityOfWordInComplement(word string, category string) float64 {
+	count := c.wordCount(word) - c.countOfWordInCategory(word, category)
+	total := c.totalCount() - c.totalCountInCategory(category)
+	if vocabularySize := float64(c.vocabularySize()); c.Alpha > 0 && vocabularySize > 0 {
+		return (count + c.Alpha) / (total + c.Alpha*vocabularySize)
+	}
+	return count / total
 }
 
 // p (category)
 func (c *Classifier) probabilityOfCategory(category string, totalCount float64) float64 {
+	if c.priors == nil {
+		return c.defaultPrior(category, totalCount)
+	}
+	sum := 0.0
+	for known := range c.CatCount {
+		sum += c.overriddenPrior(known, totalCount)
+	}
+	if sum == 0 {
+		return c.defaultPrior(category, totalCount)
+	}
+	return c.overriddenPrior(category, totalCount) / sum
+}
+
+// overriddenPrior returns the unnormalized prior of the category set with
+// SetPriors, or its default prior
+func (c *Classifier) overriddenPrior(category string, totalCount float64) float64 {
+	if prior, ok := c.priors[category]; ok {
+		return prior
+	}
+	return c.defaultPrior(category, totalCount)
+}
+
+// defaultPrior returns the prior of the category without overrides
+func (c *Classifier) defaultPrior(category string, totalCount float64) float64 {
+	if c.prior == uniformPrior {
+		return 1 / float64(len(c.CatCount))
+	}
 	return c.totalCountInCategory(category) / totalCount
 }
 
+// rank orders the probabilities descending, breaking ties by category
+func rank(probabilities map[string]float64) []CategoryScore {
+	scores := make([]CategoryScore, 0, len(probabilities))
+	for category, p := range probabilities {
+		scores = append(scores, CategoryScore{Label: category, Score: p})
+	}
+	sortScores(scores)
+	return scores
+}
+
+// normalize rescales the probabilities so they sum to 1; an empty map is
+// returned when nothing scored above zero
+func normalize(probabilities map[string]float64) map[string]float64 {
+	total := 0.0
+	for _, p := range probabilities {
+		total += p
+	}
+
+	normalized := make(map[string]float64, len(probabilities))
+	if total == 0 || math.IsNaN(total) || math.IsInf(total, 0) {
+		return normalized
+	}
+	for category, p := range probabilities {
+		normalized[category] = p / total
+	}
+	return normalized
+}
+
 func AsReader(text string) io.Reader {
 	return bytes.NewBufferString(text)
 }
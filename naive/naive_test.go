@@ -1,12 +1,21 @@
 package naive
 
 import (
-	"encoding/csv"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/carautenbach/classifier"
 )
 
 var categories = []string{
@@ -55,33 +64,1138 @@ func TestWeatherClassifier(t *testing.T) {
 
 func TestClassifier(t *testing.T) {
 	f, err := os.Open("./classification_training_data.csv")
-
+	if os.IsNotExist(err) {
+		t.Skip("training data not available")
+	}
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
+	defer f.Close()
 
 	// Train on CSV data
 	classifier := New()
-	r := csv.NewReader(f)
+	if _, err := classifier.TrainCSV(f, 0, 1, false); err != nil {
+		t.Fatalf("unable to train: %v", err)
+	}
 
-	for {
-		record, err := r.Read()
+	now := time.Now()
+	probabilities, topResult := classifier.Probabilities("Veldskoen")
 
-		if err == io.EOF {
-			break
+	fmt.Println("Calculation took: ", time.Now().Sub(now))
+	fmt.Printf("%s: %f", topResult, probabilities[topResult])
+	fmt.Println(probabilities)
+}
+
+func TestStopWords(t *testing.T) {
+	for _, tokenizer := range []*classifier.StdTokenizer{
+		classifier.NewTokenizer(),
+		classifier.NewTokenizer(classifier.WithStopWords([]string{"THE"})),
+	} {
+		c := New()
+		c.Tokenizer = tokenizer
+		c.TrainString("The white kitty", "Cat")
+		if vocabulary := c.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"kitty", "white"}) {
+			t.Errorf("Expected %v; actual: %v", []string{"kitty", "white"}, vocabulary)
 		}
+	}
+}
 
-		if err != nil {
-			panic(err)
+func TestCaseInsensitive(t *testing.T) {
+	c := New()
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("Black shepherd", "Dog")
+
+	lower, _ := c.Probabilities("white kitty")
+	upper, _ := c.Probabilities("WHITE KITTY")
+	if !reflect.DeepEqual(lower, upper) {
+		t.Errorf("Expected %v; actual: %v", lower, upper)
+	}
+
+	c = New()
+	c.Tokenizer = classifier.NewTokenizer(classifier.WithLowercase(false))
+	c.TrainString("White kitty", "Cat")
+	if _, ok := c.Feat2cat["White"]; !ok {
+		t.Errorf("Expected %q to keep its case", "White")
+	}
+}
+
+func TestStripPunctuation(t *testing.T) {
+	c := New()
+	c.Tokenizer = classifier.NewTokenizer(classifier.WithStripPunctuation(true))
+	c.TrainString("Black kitty.", "Cat")
+
+	if vocabulary := c.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"black", "kitty"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"black", "kitty"}, vocabulary)
+	}
+}
+
+func TestMinTokenLength(t *testing.T) {
+	c := New()
+	c.Tokenizer = classifier.NewTokenizer(classifier.WithMinTokenLength(3))
+	c.TrainString("a white kitty x", "Cat")
+
+	for word := range c.Feat2cat {
+		if len(word) < 3 {
+			t.Errorf("Expected %q to be dropped", word)
 		}
+	}
+}
 
-		classifier.TrainString(record[0], record[1])
+func TestRegexTokenizer(t *testing.T) {
+	tokenizer, err := classifier.NewRegexTokenizer(`[A-Z]+-\d+`)
+	if err != nil {
+		t.Fatal(err)
 	}
+	c := New()
+	c.Tokenizer = tokenizer
+	c.TrainString("Shipped ABC-123 and ABC-124", "Books")
+	c.TrainString("Shipped XYZ-900", "Toys")
 
-	now := time.Now()
-	probabilities, topResult := classifier.Probabilities("Veldskoen")
+	if vocabulary := c.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"ABC-123", "ABC-124", "XYZ-900"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"ABC-123", "ABC-124", "XYZ-900"}, vocabulary)
+	}
+	if _, topResult := c.Probabilities("Where is XYZ-900?"); topResult != "Toys" {
+		t.Errorf("Expected %s; actual: %s", "Toys", topResult)
+	}
+}
 
-	fmt.Println("Calculation took: ", time.Now().Sub(now))
-	fmt.Printf("%s: %f", topResult, probabilities[topResult])
-	fmt.Println(probabilities)
+func TestUnicodeNormalization(t *testing.T) {
+	c := New()
+	c.Tokenizer = classifier.NewTokenizer(classifier.WithUnicodeNormalization(true))
+	c.TrainString("caf\u00e9 latte", "Drink")
+	c.TrainString("white kitty", "Cat")
+
+	if _, topResult := c.Probabilities("cafe\u0301"); topResult != "Drink" {
+		t.Errorf("Expected %s; actual: %s", "Drink", topResult)
+	}
+	if vocabulary := c.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"caf\u00e9", "kitty", "latte", "white"}) {
+		t.Errorf("Expected %q; actual: %q", []string{"caf\u00e9", "kitty", "latte", "white"}, vocabulary)
+	}
+}
+
+func TestStemming(t *testing.T) {
+	c := New()
+	c.Tokenizer = classifier.NewTokenizer(classifier.WithStemmer("english"))
+	c.TrainString("White kittens", "Cat")
+	c.TrainString("Black shepherds", "Dog")
+
+	if _, topResult := c.Probabilities("kitten"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+	if _, ok := c.Feat2cat["kitten"]; !ok {
+		t.Errorf("Expected the stem %q in the vocabulary", "kitten")
+	}
+}
+
+func TestNGramTokenizer(t *testing.T) {
+	unigrams, bigrams := New(), New()
+	bigrams.Tokenizer = classifier.NewTokenizer(classifier.WithNGramRange(1, 2))
+	for _, c := range []*Classifier{unigrams, bigrams} {
+		c.TrainString("German shepherd", "Dog")
+		c.TrainString("German rex", "Cat")
+		c.TrainString("Shepherd pie", "Food")
+	}
+
+	if _, ok := bigrams.Feat2cat["german_shepherd"]; !ok {
+		t.Fatalf("Expected the bigram %q in the model", "german_shepherd")
+	}
+	without, _ := unigrams.ProbabilitiesNormalized("German Shepherd")
+	with, topResult := bigrams.ProbabilitiesNormalized("German Shepherd")
+	if topResult != "Dog" {
+		t.Errorf("Expected %s; actual: %s", "Dog", topResult)
+	}
+	if with["Dog"] <= without["Dog"] {
+		t.Errorf("Expected the bigram to raise the probability of %s: %v <= %v", "Dog", with["Dog"], without["Dog"])
+	}
+}
+
+func TestClassifyWithThreshold(t *testing.T) {
+	for _, fallback := range []string{"", "unknown"} {
+		c := New(WithFallbackCategory(fallback))
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White shepherd", "Dog")
+		c.TrainString("Black shepherd", "Dog")
+
+		label, confidence, err := c.ClassifyWithThreshold("kitty", 0.7)
+		if err != nil || label != "Cat" || confidence < 0.7 {
+			t.Errorf("Expected %s above the threshold; actual: %s, %v, %v", "Cat", label, confidence, err)
+		}
+
+		label, confidence, err = c.ClassifyWithThreshold("white", 0.8)
+		if err != nil || label != fallback || math.Abs(confidence-0.5) > 1e-9 {
+			t.Errorf("Expected %q with confidence 0.5; actual: %q, %v, %v", fallback, label, confidence, err)
+		}
+	}
+
+	if _, _, err := New().ClassifyWithThreshold("kitty", 1.5); err == nil {
+		t.Errorf("Expected an invalid threshold to return an error")
+	}
+	if _, _, err := New(WithMinTrainingDocs(1)).ClassifyWithThreshold("kitty", 0.5); !errors.Is(err, ErrInsufficientTraining) {
+		t.Errorf("Expected %v; actual: %v", ErrInsufficientTraining, err)
+	}
+}
+
+func TestProbabilitiesIgnoring(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("White shepherd", "Dog")
+	classifier.TrainString("White pointer", "Dog")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+
+	if _, topResult := classifier.Probabilities("White kitty"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+
+	if _, topResult := classifier.ProbabilitiesIgnoring("White kitty", []string{"Kitty"}); topResult != "Dog" {
+		t.Errorf("Expected %s; actual: %s", "Dog", topResult)
+	}
+
+	if _, ok := classifier.Feat2cat["kitty"]; !ok {
+		t.Errorf("ignored word was removed from the model")
+	}
+}
+
+func TestClassifyExplained(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("German shepherd", "Dog")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+
+	tests := []struct {
+		Input          string
+		Label          string
+		UsedVocabulary bool
+	}{
+		{"White kitty", "Cat", true},
+		{"German shepherd", "Dog", true},
+		{"Purple elephant", "Cat", false},
+	}
+
+	for _, test := range tests {
+		label, usedVocabulary := classifier.ClassifyExplained(test.Input)
+		if label != test.Label || usedVocabulary != test.UsedVocabulary {
+			t.Errorf("%s: expected (%s, %t); actual: (%s, %t)", test.Input, test.Label, test.UsedVocabulary, label, usedVocabulary)
+		}
+	}
+}
+
+func TestClassifyWithCosts(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Cash meeting", "ham")
+	classifier.TrainString("Cash notes", "ham")
+	classifier.TrainString("Cash prize", "spam")
+
+	if _, topResult := classifier.Probabilities("Cash"); topResult != "ham" {
+		t.Fatalf("Expected %s; actual: %s", "ham", topResult)
+	}
+	if actual := classifier.ClassifyWithCosts("Cash", nil); actual != "ham" {
+		t.Errorf("Expected 0/1 loss to match the argmax %s; actual: %s", "ham", actual)
+	}
+
+	costs := map[string]map[string]float64{
+		"spam": {"ham": 5},
+	}
+	if actual := classifier.ClassifyWithCosts("Cash", costs); actual != "spam" {
+		t.Errorf("Expected %s; actual: %s", "spam", actual)
+	}
+}
+
+func TestPredictionEntropy(t *testing.T) {
+	classifier := New()
+
+	if entropy := classifier.PredictionEntropy("Fluffy"); entropy != 0 {
+		t.Errorf("Expected an untrained entropy of 0; actual: %f", entropy)
+	}
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+
+	uncertain := classifier.PredictionEntropy("Fluffy")
+	if math.Abs(uncertain-math.Log(2)) > 1e-9 {
+		t.Errorf("Expected entropy %f; actual: %f", math.Log(2), uncertain)
+	}
+
+	if certain := classifier.PredictionEntropy("Kitty"); certain >= uncertain {
+		t.Errorf("Expected a peaked posterior to have lower entropy than %f; actual: %f", uncertain, certain)
+	}
+}
+
+func TestFeaturelessCategory(t *testing.T) {
+	classifier := New(WithProbabilityFloor(0.5))
+
+	classifier.TrainString("German shepherd", "Dog")
+	classifier.TrainString("Black kitty", "Cat")
+	for i := 0; i < 3; i++ {
+		classifier.TrainString("The a is", "Empty")
+	}
+
+	for _, input := range []string{"Kitty shepherd", "Kitty", "Purple elephant"} {
+		probabilities, topResult := classifier.Probabilities(input)
+		if topResult == "Empty" {
+			t.Errorf("%s: expected the featureless category not to win", input)
+		}
+		for category, p := range probabilities {
+			if math.IsNaN(p) {
+				t.Errorf("%s: expected a number for %s; actual: NaN", input, category)
+			}
+		}
+	}
+
+	if _, topResult := classifier.Probabilities("The"); topResult != "Empty" {
+		t.Errorf("Expected the largest prior %s to win an empty input; actual: %s", "Empty", topResult)
+	}
+}
+
+func TestBackgroundProbability(t *testing.T) {
+	classifier := New()
+
+	if p := classifier.BackgroundProbability("kitty"); p != 0 {
+		t.Errorf("Expected 0 for an empty model; actual: %f", p)
+	}
+
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White puppy", "Dog")
+
+	tests := []struct {
+		Word     string
+		Expected float64
+	}{
+		{"white", 2.0 / 6.0},
+		{"kitty", 2.0 / 6.0},
+		{"puppy", 1.0 / 6.0},
+		{"guppy", 0},
+	}
+
+	hashed := New(WithFeatureHashingSalt("pepper"))
+	hashed.TrainString("White kitty", "Cat")
+	hashed.TrainString("Black kitty", "Cat")
+	hashed.TrainString("White puppy", "Dog")
+
+	for _, test := range tests {
+		assertFloat(t, test.Word, test.Expected, classifier.BackgroundProbability(test.Word))
+		assertFloat(t, test.Word, test.Expected, hashed.BackgroundProbability(test.Word))
+	}
+}
+
+func TestClassifyWithinGap(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy guppy", "Fish")
+
+	tests := []struct {
+		Input    string
+		Expected []string
+	}{
+		{"Kitty", []string{"Cat"}},
+		{"Fluffy", []string{"Cat", "Dog"}},
+	}
+
+	for _, test := range tests {
+		actual := classifier.ClassifyWithinGap(test.Input, 0.2)
+		if fmt.Sprint(actual) != fmt.Sprint(test.Expected) {
+			t.Errorf("%s: expected %v; actual: %v", test.Input, test.Expected, actual)
+		}
+	}
+
+	if actual := New().ClassifyWithinGap("Kitty", 0.2); actual != nil {
+		t.Errorf("Expected no categories for an untrained model; actual: %v", actual)
+	}
+}
+
+// cancellingTokenizer cancels a context once a document has been tokenized,
+// so the cancellation arrives while the categories are being scored
+type cancellingTokenizer struct {
+	classifier.Tokenizer
+	cancel context.CancelFunc
+}
+
+func (t cancellingTokenizer) Tokenize(r io.Reader) chan string {
+	tokens := make(chan string)
+	go func() {
+		for token := range t.Tokenizer.Tokenize(r) {
+			tokens <- token
+		}
+		t.cancel()
+		close(tokens)
+	}()
+	return tokens
+}
+
+func TestProbabilitiesContext(t *testing.T) {
+	var buf bytes.Buffer
+	c := New(WithLogger(log.New(&buf, "", 0)))
+	for i := 0; i < 100; i++ {
+		c.TrainString("kitty number "+fmt.Sprint(i), fmt.Sprint("Category", i))
+	}
+
+	probabilities, topResult, err := c.ProbabilitiesContext(context.Background(), "kitty")
+	if err != nil || topResult == "" || len(probabilities) != 100 {
+		t.Errorf("Expected every category to be scored; actual: %d, %q, %v", len(probabilities), topResult, err)
+	}
+
+	buf.Reset()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if probabilities, _, err := c.ProbabilitiesContext(ctx, "kitty"); !errors.Is(err, context.Canceled) || probabilities != nil {
+		t.Errorf("Expected %v; actual: %v, %v", context.Canceled, probabilities, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no categories to be scored; actual: %s", buf.String())
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if _, _, err := c.ProbabilitiesContext(ctx, "kitty"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v; actual: %v", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	c.Tokenizer = cancellingTokenizer{Tokenizer: c.Tokenizer, cancel: cancel}
+	if _, _, err := c.ProbabilitiesContext(ctx, "kitty"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v; actual: %v", context.Canceled, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected scoring to be abandoned; actual: %s", buf.String())
+	}
+}
+
+func TestClassifyMulti(t *testing.T) {
+	c := New()
+	c.TrainString("football match goal", "Sports")
+	c.TrainString("election vote parliament", "Politics")
+	c.TrainString("guitar concert album", "Music")
+
+	labels, err := c.ClassifyMulti("vote on the football match", 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(labels, []string{"Sports", "Politics"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"Sports", "Politics"}, labels)
+	}
+
+	labels, err = c.ClassifyMulti("guitar album", 0.2)
+	if err != nil || !reflect.DeepEqual(labels, []string{"Music"}) {
+		t.Errorf("Expected %v; actual: %v, %v", []string{"Music"}, labels, err)
+	}
+
+	labels, err = c.ClassifyMulti("guitar album", 1)
+	if err != nil || labels == nil || len(labels) != 0 {
+		t.Errorf("Expected an empty slice; actual: %#v, %v", labels, err)
+	}
+
+	if _, err := c.ClassifyMulti("guitar", -0.1); err == nil {
+		t.Errorf("Expected an invalid minimum score to return an error")
+	}
+}
+
+func TestTopN(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy guppy", "Fish")
+
+	tests := []struct {
+		Input    string
+		N        int
+		Expected []string
+	}{
+		{"Kitty", 1, []string{"Cat"}},
+		{"Guppy", 2, []string{"Fish", "Cat"}},
+		{"Fluffy", 5, []string{"Cat", "Dog", "Fish"}},
+		{"Fluffy", 0, []string{}},
+	}
+
+	for _, test := range tests {
+		results := classifier.TopN(test.Input, test.N)
+		actual := make([]string, len(results))
+		for i, result := range results {
+			actual[i] = result.Category
+			if i > 0 && result.Score > results[i-1].Score {
+				t.Errorf("%s: expected descending scores; actual: %v", test.Input, results)
+			}
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(test.Expected) {
+			t.Errorf("%s: expected %v; actual: %v", test.Input, test.Expected, actual)
+		}
+	}
+}
+
+func TestDebugTokens(t *testing.T) {
+	stem := func(s string) string {
+		return strings.TrimSuffix(s, "s")
+	}
+
+	c := New()
+	c.Tokenizer = classifier.NewTokenizer(
+		classifier.Filters(classifier.IsNotStopWord),
+		classifier.Transforms(strings.ToLower, stem),
+	)
+
+	expected := []string{"kitten", "chase", "mice"}
+	actual := c.DebugTokens("The Kittens chase the mice")
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+
+	// tokens are reported as the tokenizer produced them, not as features
+	hashed := New(WithFeatureHashingSalt("pepper"), WithUniqueInputTokens(), WithNGramMixture(map[int]float64{1: 1, 2: 1}))
+	expected = []string{"kitty", "chases", "kitty"}
+	if actual := hashed.DebugTokens("Kitty chases kitty"); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+}
+
+func TestClassifyWithStats(t *testing.T) {
+	c := New()
+
+	c.TrainString("Black kitty", "Cat")
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("German shepherd", "Dog")
+
+	label, stats := c.ClassifyWithStats("The white kitty is a purple unicorn")
+	if label != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", label)
+	}
+
+	expected := ClassifyStats{Total: 7, Known: 2, Unknown: 2, Dropped: 3}
+	if stats != expected {
+		t.Errorf("Expected %+v; actual: %+v", expected, stats)
+	}
+}
+
+func TestLikelihoodScores(t *testing.T) {
+	c := New()
+
+	c.TrainString("German shepherd", "Dog")
+	c.TrainString("White pointer", "Dog")
+	c.TrainString("Black kitty", "Cat")
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("White kitten", "Cat")
+
+	probabilities, _ := c.Probabilities("White")
+	logScores := c.LogScores("White")
+	likelihoods := c.LikelihoodScores("White")
+	if len(logScores) != 2 || len(likelihoods) != 2 {
+		t.Fatalf("Expected scores for 2 categories; actual: %v, %v", logScores, likelihoods)
+	}
+
+	for category, score := range logScores {
+		prior := math.Log(c.CatCount[category] / 5)
+		assertFloat(t, category, score, likelihoods[category]+prior)
+		assertFloat(t, category, math.Log(probabilities[category]), score)
+	}
+}
+
+func TestClassifyEncoded(t *testing.T) {
+	c := New()
+	c.TrainString("Café crème brûlée", "Dessert")
+	c.TrainString("Steak frites", "Main")
+
+	// "crème brûlée" encoded as windows-1252
+	input := []byte{'c', 'r', 0xe8, 'm', 'e', ' ', 'b', 'r', 0xfb, 'l', 0xe9, 'e'}
+
+	if actual, err := c.ClassifyEncoded(bytes.NewReader(input), "windows-1252"); err != nil || actual != "Dessert" {
+		t.Errorf("Expected %s; actual: %s, %v", "Dessert", actual, err)
+	}
+	if _, usedVocabulary := c.ClassifyExplained(string(input)); usedVocabulary {
+		t.Errorf("Expected raw windows-1252 bytes not to match the UTF-8 vocabulary")
+	}
+	if _, err := c.ClassifyEncoded(bytes.NewReader(input), "klingon"); err == nil {
+		t.Errorf("Expected an error for an unsupported encoding")
+	}
+}
+
+func TestClassifyOrReject(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy puppy", "Dog")
+
+	tests := []struct {
+		Input    string
+		Expected string
+		Accepted bool
+	}{
+		{"Kitty", "Cat", true},
+		{"Zebra giraffe", "", false},
+		{"Fluffy", "", false},
+	}
+
+	for _, test := range tests {
+		label, accepted := classifier.ClassifyOrReject(test.Input)
+		if label != test.Expected || accepted != test.Accepted {
+			t.Errorf("%s: expected %q, %v; actual: %q, %v", test.Input, test.Expected, test.Accepted, label, accepted)
+		}
+	}
+}
+
+func TestPosteriorMatrix(t *testing.T) {
+	classifier := New(WithProbabilityFloor(0.01))
+
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+	classifier.TrainString("Guppy", "Fish")
+
+	inputs := []string{"kitty", "shepherd", "guppy kitty"}
+	categories, matrix := classifier.PosteriorMatrix(inputs)
+
+	if expected := []string{"Cat", "Dog", "Fish"}; fmt.Sprint(categories) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v; actual: %v", expected, categories)
+	}
+
+	for i, row := range matrix {
+		sum, best := 0.0, 0
+		for j, p := range row {
+			sum += p
+			if p > row[best] {
+				best = j
+			}
+		}
+		assertFloat(t, inputs[i], 1, sum)
+
+		predicted, _ := classifier.Predict(inputs[i])
+		if categories[best] != predicted {
+			t.Errorf("%s: expected %s; actual: %s", inputs[i], predicted, categories[best])
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+	classifier := New()
+	if categories := classifier.Categories(); len(categories) != 0 {
+		t.Errorf("Expected no categories; actual: %v", categories)
+	}
+
+	classifier.TrainString("Puppy", "Dog")
+	classifier.TrainString("Kitty", "Cat")
+	classifier.TrainString("Parrot", "Bird")
+	expected := []string{"Bird", "Cat", "Dog"}
+	if categories := classifier.Categories(); !reflect.DeepEqual(categories, expected) {
+		t.Errorf("Expected %v; actual: %v", expected, categories)
+	}
+}
+
+func TestPriors(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("Fluffy kitten", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	priors := classifier.Priors()
+	assertFloat(t, "Cat", 0.75, priors["Cat"])
+	assertFloat(t, "Dog", 0.25, priors["Dog"])
+	assertFloat(t, "sum", 1, priors["Cat"]+priors["Dog"])
+
+	uniform := New(WithUniformPrior())
+	uniform.TrainString("Black kitty", "Cat")
+	uniform.TrainString("White kitty", "Cat")
+	uniform.TrainString("German shepherd", "Dog")
+	assertFloat(t, "uniform", 0.5, uniform.Priors()["Cat"])
+
+	if actual := New().Priors(); len(actual) != 0 {
+		t.Errorf("Expected no priors; actual: %v", actual)
+	}
+}
+
+func TestSetPriors(t *testing.T) {
+	c := New()
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("Black kitty", "Cat")
+	c.TrainString("White shepherd", "Dog")
+
+	if _, topResult := c.ProbabilitiesNormalized("white"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+
+	if err := c.SetPriors(map[string]float64{"Cat": 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, topResult := c.ProbabilitiesNormalized("white"); topResult != "Dog" {
+		t.Errorf("Expected %s; actual: %s", "Dog", topResult)
+	}
+	priors := c.Priors()
+	assertFloat(t, "Cat", 0.1/(0.1+1.0/3), priors["Cat"])
+	assertFloat(t, "Dog", (1.0/3)/(0.1+1.0/3), priors["Dog"])
+
+	if err := c.SetPriors(map[string]float64{"Cat": -1}); err == nil {
+		t.Errorf("Expected a negative prior to return an error")
+	}
+	assertFloat(t, "Cat", priors["Cat"], c.Priors()["Cat"])
+
+	if err := c.SetPriors(nil); err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "Cat", 2.0/3, c.Priors()["Cat"])
+}
+
+func TestAddObservations(t *testing.T) {
+	for _, opts := range [][]Option{{WithProbabilityFloor(0.01)}, {WithProbabilityFloor(0.01), WithFeatureHashingSalt("pepper")}} {
+		replayed := New(opts...)
+		replayed.TrainString("White kitty", "Cat")
+		replayed.TrainString("Black kitty", "Cat")
+		replayed.TrainString("White shepherd", "Dog")
+
+		aggregated := New(opts...)
+		aggregated.AddObservations("white", "Cat", 1, 2)
+		aggregated.AddObservations("kitty", "Cat", 2, 0)
+		aggregated.AddObservations("black", "Cat", 1, 0)
+		aggregated.AddObservations("white", "Dog", 1, 1)
+		aggregated.AddObservations("shepherd", "Dog", 1, 0)
+
+		if !reflect.DeepEqual(aggregated.Feat2cat, replayed.Feat2cat) {
+			t.Errorf("Expected the words stored as trained; actual: %v", aggregated.Feat2cat)
+		}
+		for _, input := range []string{"Kitty", "White kitty", "Shepherd"} {
+			expected, expectedTop := replayed.Probabilities(input)
+			actual, actualTop := aggregated.Probabilities(input)
+			if expectedTop != actualTop {
+				t.Errorf("%s: expected %s; actual: %s", input, expectedTop, actualTop)
+			}
+			for category, p := range expected {
+				assertFloat(t, input+" "+category, p, actual[category])
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for negative observations")
+		}
+	}()
+	New().AddObservations("kitty", "Cat", -1, 0)
+}
+
+func TestTrainWeighted(t *testing.T) {
+	twice := New()
+	twice.TrainString("White kitty", "Cat")
+	twice.TrainString("White kitty", "Cat")
+	twice.TrainString("German shepherd", "Dog")
+
+	weighted := New()
+	if err := weighted.TrainWeighted(AsReader("White kitty"), "Cat", 2); err != nil {
+		t.Fatalf("unable to train: %v", err)
+	}
+	weighted.TrainString("German shepherd", "Dog")
+
+	if !reflect.DeepEqual(twice.Feat2cat, weighted.Feat2cat) {
+		t.Errorf("Expected %v; actual: %v", twice.Feat2cat, weighted.Feat2cat)
+	}
+	if !reflect.DeepEqual(twice.CatCount, weighted.CatCount) {
+		t.Errorf("Expected %v; actual: %v", twice.CatCount, weighted.CatCount)
+	}
+	for _, input := range []string{"Kitty", "White shepherd"} {
+		expected, _ := twice.Probabilities(input)
+		actual, _ := weighted.Probabilities(input)
+		for category, p := range expected {
+			assertFloat(t, input+" "+category, p, actual[category])
+		}
+	}
+
+	weighted.TrainWeighted(AsReader("Tabby"), "Cat", 0.5)
+	if count := weighted.WordCountInCategory("tabby", "Cat"); count != 0.5 {
+		t.Errorf("Expected %v; actual: %v", 0.5, count)
+	}
+	if count := weighted.TotalDocumentCount(); count != 3.5 {
+		t.Errorf("Expected %v; actual: %v", 3.5, count)
+	}
+
+	for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if err := weighted.TrainWeighted(AsReader("Kitty"), "Cat", weight); err == nil {
+			t.Errorf("Expected an error for weight %v", weight)
+		}
+	}
+}
+
+func TestUntrain(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+	feat2cat := fmt.Sprint(classifier.Feat2cat)
+	catCount := fmt.Sprint(classifier.CatCount)
+
+	classifier.TrainString("Black kitty kitty", "Cat")
+	classifier.TrainString("Black labrador", "Bird")
+	if err := classifier.UntrainString("Black kitty kitty", "Cat"); err != nil {
+		t.Fatalf("unable to untrain: %v", err)
+	}
+	if err := classifier.UntrainString("Black labrador", "Bird"); err != nil {
+		t.Fatalf("unable to untrain: %v", err)
+	}
+	if actual := fmt.Sprint(classifier.Feat2cat); actual != feat2cat {
+		t.Errorf("Expected %v; actual: %v", feat2cat, actual)
+	}
+	if actual := fmt.Sprint(classifier.CatCount); actual != catCount {
+		t.Errorf("Expected %v; actual: %v", catCount, actual)
+	}
+
+	for _, untrained := range []struct{ text, category string }{
+		{"White kitty", "Dog"},
+		{"White white kitty", "Cat"},
+		{"Kitty", "Bird"},
+	} {
+		if err := classifier.UntrainString(untrained.text, untrained.category); !errors.Is(err, ErrNotTrained) {
+			t.Errorf("%s: expected %v; actual: %v", untrained.text, ErrNotTrained, err)
+		}
+	}
+	if actual := fmt.Sprint(classifier.Feat2cat); actual != feat2cat {
+		t.Errorf("Expected a failed untrain to leave the model unchanged; actual: %v", actual)
+	}
+}
+
+func TestReset(t *testing.T) {
+	classifier := New(WithCaseFallback())
+	classifier.SetAlpha(0.5)
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	classifier.Reset()
+	if categories := classifier.Categories(); len(categories) != 0 {
+		t.Errorf("Expected no categories; actual: %v", categories)
+	}
+	if probabilities, topResult := classifier.Probabilities("White kitty"); len(probabilities) != 0 || topResult != "" {
+		t.Errorf("Expected no probabilities; actual: %v, %q", probabilities, topResult)
+	}
+	if classifier.Alpha != 0.5 {
+		t.Errorf("Expected %v; actual: %v", 0.5, classifier.Alpha)
+	}
+
+	classifier.TrainString("Tabby", "Cat")
+	classifier.TrainString("Poodle", "Dog")
+	if _, topResult := classifier.Probabilities("TABBY"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+	if count := classifier.WordCount("kitty"); count != 0 {
+		t.Errorf("Expected %v; actual: %v", 0, count)
+	}
+}
+
+func TestRemoveCategory(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+	feat2cat := fmt.Sprint(classifier.Feat2cat)
+	catCount := fmt.Sprint(classifier.CatCount)
+
+	classifier.TrainString("White parrot", "Bird")
+	classifier.TrainString("Talking parrot", "Bird")
+	if err := classifier.RemoveCategory("Bird"); err != nil {
+		t.Fatalf("unable to remove: %v", err)
+	}
+	if actual := fmt.Sprint(classifier.Feat2cat); actual != feat2cat {
+		t.Errorf("Expected %v; actual: %v", feat2cat, actual)
+	}
+	if actual := fmt.Sprint(classifier.CatCount); actual != catCount {
+		t.Errorf("Expected %v; actual: %v", catCount, actual)
+	}
+	if categories := classifier.Categories(); fmt.Sprint(categories) != "[Cat Dog]" {
+		t.Errorf("Expected %v; actual: %v", "[Cat Dog]", categories)
+	}
+	if probabilities, _ := classifier.Probabilities("Talking parrot"); len(probabilities) != 2 {
+		t.Errorf("Expected %d categories; actual: %v", 2, probabilities)
+	}
+
+	if err := classifier.RemoveCategory("Bird"); err == nil {
+		t.Errorf("Expected an error removing an unknown category")
+	}
+}
+
+func TestSoftmax(t *testing.T) {
+	classifier := New(WithProbabilityFloor(0.01))
+
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("White shepherd", "Dog")
+
+	normalized, _ := classifier.ProbabilitiesNormalized("White kitty")
+	neutral := classifier.Softmax("White kitty", 1)
+	sharp := classifier.Softmax("White kitty", 0.5)
+	flat := classifier.Softmax("White kitty", 4)
+
+	assertFloat(t, "neutral", normalized["Cat"], neutral["Cat"])
+	for name, softmax := range map[string]map[string]float64{"sharp": sharp, "flat": flat} {
+		assertFloat(t, name, 1, softmax["Cat"]+softmax["Dog"])
+	}
+	if !(sharp["Cat"] > neutral["Cat"] && neutral["Cat"] > flat["Cat"] && flat["Cat"] > 0.5) {
+		t.Errorf("Expected sharp > neutral > flat > 0.5; actual: %f, %f, %f", sharp["Cat"], neutral["Cat"], flat["Cat"])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for a zero temperature")
+		}
+	}()
+	classifier.Softmax("White kitty", 0)
+}
+
+// BenchmarkProbabilities scores a document against a model with many
+// categories, where every category shares the document's words
+func BenchmarkProbabilities(b *testing.B) {
+	classifier := New(WithProbabilityFloor(0.01))
+	for i := 0; i < 200; i++ {
+		category := fmt.Sprintf("Category%d", i)
+		classifier.TrainString(fmt.Sprintf("shared words for everyone plus unique%d", i), category)
+	}
+	input := "shared words for everyone plus unique7 and unique42"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		classifier.Probabilities(input)
+	}
+}
+
+func TestClassifyWithMinTokens(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	tests := []struct {
+		Input    string
+		Expected string
+		Accepted bool
+	}{
+		{"Kitty zebra giraffe", "", false},
+		{"Fluffy kitty zebra", "Cat", true},
+		{"Zebra", "", false},
+	}
+
+	for _, test := range tests {
+		label, accepted := classifier.ClassifyWithMinTokens(test.Input, 2)
+		if label != test.Expected || accepted != test.Accepted {
+			t.Errorf("%s: expected %q, %v; actual: %q, %v", test.Input, test.Expected, test.Accepted, label, accepted)
+		}
+	}
+}
+
+func TestTokenCoverage(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("White fluffy kitty", "Cat")
+	classifier.TrainString("White shepherd", "Dog")
+
+	coverage := classifier.TokenCoverage("Fluffy white kitty")
+	assertFloat(t, "Cat", 1, coverage["Cat"])
+	assertFloat(t, "Dog", 1.0/3, coverage["Dog"])
+
+	if actual := classifier.TokenCoverage(""); len(actual) != 0 {
+		t.Errorf("Expected no coverage; actual: %v", actual)
+	}
+}
+
+func TestAlpha(t *testing.T) {
+	train := func(c *Classifier) {
+		c.TrainString("German shepherd", "Dog")
+		c.TrainString("White kitty", "Cat")
+	}
+
+	smoothed := New()
+	train(smoothed)
+	probabilities, topResult := smoothed.Probabilities("German poodle")
+	if probabilities["Dog"] <= 0 || topResult != "Dog" {
+		t.Errorf("Expected a positive Dog probability; actual: %v", probabilities)
+	}
+
+	unsmoothed := New()
+	unsmoothed.SetAlpha(0)
+	train(unsmoothed)
+	if probabilities, _ := unsmoothed.Probabilities("German poodle"); probabilities["Dog"] != 0 {
+		t.Errorf("Expected an unseen word to zero Dog without smoothing; actual: %v", probabilities)
+	}
+
+	lidstone := New()
+	lidstone.SetAlpha(0.5)
+	train(lidstone)
+	assertFloat(t, "P(german|Dog)", (1+0.5)/(1+0.5*4), lidstone.probabilityOfWordInCategory("german", "Dog"))
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for a negative alpha")
+		}
+	}()
+	lidstone.SetAlpha(-1)
+}
+
+func TestProbabilitiesNormalized(t *testing.T) {
+	weather := New()
+	for _, example := range []Example{
+		{"Sunny", "No"}, {"Sunny", "No"}, {"Overcast", "Yes"}, {"Rainy", "Yes"},
+		{"Rainy", "Yes"}, {"Rainy", "No"}, {"Overcast", "Yes"}, {"Sunny", "No"},
+		{"Sunny", "Yes"}, {"Rainy", "Yes"}, {"Sunny", "Yes"}, {"Overcast", "Yes"},
+		{"Overcast", "Yes"}, {"Rainy", "No"},
+	} {
+		weather.TrainString(example.Text, example.Category)
+	}
+
+	for _, input := range []string{"Overcast", "Sunny", strings.Repeat("Sunny rainy ", 200)} {
+		probabilities, topResult := weather.ProbabilitiesNormalized(input)
+		assertFloat(t, input, 1, probabilities["Yes"]+probabilities["No"])
+		if probabilities[topResult] < 0.5 {
+			t.Errorf("%s: expected %s to be most likely; actual: %v", input, topResult, probabilities)
+		}
+	}
+
+	// without smoothing Dog cannot score at all and must not turn into NaN
+	unsmoothed := New()
+	unsmoothed.SetAlpha(0)
+	unsmoothed.TrainString("White kitty", "Cat")
+	unsmoothed.TrainString("German shepherd", "Dog")
+	probabilities, _ := unsmoothed.ProbabilitiesNormalized("Kitty")
+	if len(probabilities) != 1 || probabilities["Cat"] != 1 {
+		t.Errorf("Expected only Cat; actual: %v", probabilities)
+	}
+}
+
+// TestProbabilityGroupedConcurrent scores categories across several groups
+// sharing one map and mutex; run with -race to verify the locking
+func TestProbabilityGroupedConcurrent(t *testing.T) {
+	classifier := New()
+	var categories []string
+	for i := 0; i < 40; i++ {
+		category := fmt.Sprintf("Category%d", i)
+		categories = append(categories, category)
+		classifier.TrainString(fmt.Sprintf("shared word%d", i), category)
+	}
+	features := classifier.features("shared word7")
+	totalCount := classifier.totalCount()
+	background := classifier.wordProbabilities(features, totalCount)
+
+	expected := make(map[string]float64)
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	wg.Add(1)
+	probabilityGrouped(context.Background(), classifier, categories, features, background, expected, totalCount, &wg, 0, len(categories), &lock)
+
+	const groups, groupSize = 8, 5
+	actual := make(map[string]float64)
+	wg.Add(groups)
+	for i := 0; i < groups; i++ {
+		go probabilityGrouped(context.Background(), classifier, categories, features, background, actual, totalCount, &wg, i*groupSize, groupSize, &lock)
+	}
+	wg.Wait()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	classifier := New()
+	for i := 0; i < 100; i++ {
+		classifier.TrainString(fmt.Sprintf("shared tied word%d", i%10), fmt.Sprintf("Category%02d", i))
+	}
+
+	classifier.Concurrency = 1
+	expected, expectedTop := classifier.Probabilities("shared word3")
+	if expectedTop != "Category03" {
+		t.Errorf("Expected the first of the tied categories; actual: %s", expectedTop)
+	}
+
+	for _, concurrency := range []int{0, 3, 8, 1000} {
+		classifier.Concurrency = concurrency
+		for i := 0; i < 10; i++ {
+			actual, actualTop := classifier.Probabilities("shared word3")
+			if actualTop != expectedTop || !reflect.DeepEqual(expected, actual) {
+				t.Fatalf("concurrency %d: expected %s %v; actual: %s %v", concurrency, expectedTop, expected, actualTop, actual)
+			}
+		}
+	}
+}
+
+func BenchmarkConcurrency(b *testing.B) {
+	classifier := New()
+	for i := 0; i < 5000; i++ {
+		classifier.TrainString(fmt.Sprintf("shared words for everyone plus unique%d", i), fmt.Sprintf("Category%d", i))
+	}
+	input := "shared words for everyone plus unique7 and unique42"
+
+	for _, concurrency := range []int{1, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			classifier.Concurrency = concurrency
+			for i := 0; i < b.N; i++ {
+				classifier.Probabilities(input)
+			}
+		})
+	}
+}
+
+func TestDominatedCategories(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy white kitty", "Cat")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+	classifier.TrainString("White pointer", "Dog")
+	classifier.TrainString("The", "Useless")
+
+	samples := []string{"Kitty", "White kitty", "Shepherd", "Black pointer"}
+	if actual := classifier.DominatedCategories(samples); fmt.Sprint(actual) != "[Useless]" {
+		t.Errorf("Expected %v; actual: %v", []string{"Useless"}, actual)
+	}
+}
+
+func TestConfidenceLift(t *testing.T) {
+	classifier := New()
+
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("Fluffy puppy", "Dog")
+	classifier.TrainString("Fluffy puppy", "Dog")
+
+	label, confident := classifier.ConfidenceLift("Kitty kitty")
+	if label != "Cat" || confident < 1.5 {
+		t.Errorf("Expected a high lift for Cat; actual: %s %f", label, confident)
+	}
+
+	_, ambiguous := classifier.ConfidenceLift("Fluffy")
+	assertFloat(t, "ambiguous", 1, ambiguous)
+
+	if label, lift := New().ConfidenceLift("Kitty"); label != "" || lift != 0 {
+		t.Errorf("Expected no lift for an untrained model; actual: %s %f", label, lift)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("Fluffy kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unable to create pipe: %v", err)
+	}
+	os.Stdout = w
+	classifier.Probabilities("Kitty")
+	os.Stdout = stdout
+	w.Close()
+	if printed, _ := io.ReadAll(r); len(printed) != 0 {
+		t.Errorf("Expected no output without a logger; actual: %q", printed)
+	}
+
+	var buf bytes.Buffer
+	classifier.Logger = log.New(&buf, "", 0)
+	classifier.Probabilities("Kitty")
+	for _, expected := range []string{"category Cat scored", "category Dog scored", "scored 1 features against 2 categories"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %q in the log; actual: %q", expected, buf.String())
+		}
+	}
 }
@@ -0,0 +1,399 @@
+package naive
+
+import (
+	"compress/gzip"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/carautenbach/classifier"
+)
+
+// Option provides configuration settings for a Classifier
+type Option func(*Classifier)
+
+// WithTokenizer sets the Tokenizer used for training and classification. A
+// nil tokenizer keeps the standard tokenizer.
+func WithTokenizer(tokenizer classifier.Tokenizer) Option {
+	return func(c *Classifier) {
+		if tokenizer != nil {
+			c.Tokenizer = tokenizer
+		}
+	}
+}
+
+// WithAlpha sets the additive smoothing strength, see Alpha; 0 disables
+// smoothing. It panics if alpha is negative.
+func WithAlpha(alpha float64) Option {
+	if !(alpha >= 0) {
+		panic(fmt.Sprintf("invalid alpha: %v", alpha))
+	}
+
+	return func(c *Classifier) {
+		c.Alpha = alpha
+	}
+}
+
+// WithConcurrency sets the number of goroutines categories are scored across,
+// see Concurrency; values below 1 score serially
+func WithConcurrency(n int) Option {
+	return func(c *Classifier) {
+		c.Concurrency = n
+	}
+}
+
+// WithLogger sets the Logger that receives diagnostics; nil keeps the
+// classifier silent
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Classifier) {
+		c.Logger = logger
+	}
+}
+
+// WithStopWords replaces the Tokenizer with a standard tokenizer that drops
+// the supplied stop words instead of the built-in english ones; an empty list
+// keeps every word. To combine custom stop words with other tokenizer
+// settings, pass classifier.WithStopWords to WithTokenizer instead.
+func WithStopWords(words []string) Option {
+	return func(c *Classifier) {
+		c.Tokenizer = classifier.NewTokenizer(classifier.WithStopWords(words))
+	}
+}
+
+// WithLowercase replaces the Tokenizer with a standard tokenizer that case
+// folds tokens, the default, or keeps their case when lowercase is false. To
+// combine it with other tokenizer settings, pass classifier.WithLowercase to
+// WithTokenizer instead.
+func WithLowercase(lowercase bool) Option {
+	return func(c *Classifier) {
+		c.Tokenizer = classifier.NewTokenizer(classifier.WithLowercase(lowercase))
+	}
+}
+
+// WithProbabilityFloor substitutes epsilon for any per-word probability that
+// computes to exactly zero, so a word never seen in a category no longer
+// zeroes that category's whole product. This is a stopgap for the legacy
+// estimator rather than proper smoothing: every unseen word is given the
+// same arbitrary probability regardless of the category's size. It only
+// applies once smoothing is disabled with SetAlpha(0).
+func WithProbabilityFloor(epsilon float64) Option {
+	return func(c *Classifier) {
+		c.floor = epsilon
+	}
+}
+
+// WithCountMinSketch stores word counts in a count-min sketch of the given
+// width and depth instead of the exact Feat2cat maps, bounding memory use
+// regardless of vocabulary size. Counts read back from the sketch are
+// estimates that may be over-counted when (word, category) pairs collide, but
+// never under-counted; a larger width reduces the overestimation and a
+// larger depth makes large errors less likely. Feat2cat stays empty in this
+// mode, so methods that enumerate the vocabulary see no words; the vocabulary
+// size used for smoothing is estimated. It panics unless width and depth are
+// positive.
+func WithCountMinSketch(width, depth int) Option {
+	if width < 1 || depth < 1 {
+		panic(fmt.Sprintf("invalid count-min sketch dimensions: %dx%d", width, depth))
+	}
+
+	return func(c *Classifier) {
+		c.sketch = newCountMinSketch(width, depth)
+	}
+}
+
+// WithDisplayFloor raises every normalized probability returned by
+// ProbabilitiesNormalized to at least epsilon and renormalizes, so no
+// category is presented as exactly zero. It is purely a presentation concern
+// and does not affect scoring or the chosen category.
+func WithDisplayFloor(epsilon float64) Option {
+	return func(c *Classifier) {
+		c.display = epsilon
+	}
+}
+
+// WithAdaptiveSmoothing applies additive smoothing with a per-category
+// strength instead of using raw frequencies:
+//
+//	alpha(c)   = V / (V + N(c))
+//	P(word|c)  = (count(word, c) + alpha(c)) / (documents(c) + alpha(c) * V)
+//
+// where V is the vocabulary size and N(c) the number of tokens trained into
+// the category. Small categories are smoothed almost as if alpha were 1 while
+// the smoothing of large categories fades as their evidence grows. It takes
+// precedence over the classifier's Alpha.
+func WithAdaptiveSmoothing() Option {
+	return func(c *Classifier) {
+		c.adaptive = true
+	}
+}
+
+// ngramSeparator joins the words of an n-gram feature
+const ngramSeparator = "_"
+
+// WithNGramMixture generates n-gram features for every order n in weights,
+// both during training and classification, joining the words of each n-gram
+// with an underscore. At classification time the contribution of an n-gram
+// is weighted by the weight of its order, e.g. {1: 0.7, 2: 0.3} mixes
+// unigrams and bigrams. Orders missing from weights are not generated at all.
+// It panics if any order is not a positive integer.
+func WithNGramMixture(weights map[int]float64) Option {
+	for n := range weights {
+		if n < 1 {
+			panic(fmt.Sprintf("invalid n-gram order: %d", n))
+		}
+	}
+
+	return func(c *Classifier) {
+		c.ngrams = make(map[int]float64, len(weights))
+		for n, weight := range weights {
+			c.ngrams[n] = weight
+		}
+	}
+}
+
+// WithExampleRetention keeps a copy of every trained document so the model can
+// later be audited, e.g. with SuspiciousExamples. Retention holds the full
+// text of the training set in memory.
+func WithExampleRetention() Option {
+	return func(c *Classifier) {
+		c.retain = true
+	}
+}
+
+// WithCaseFallback makes a lookup of a word the model has never seen fall
+// back to every stored word that equals it ignoring case, so a tokenizer that
+// preserves case can keep "HTTP" distinct while "http" still matches it.
+// Exact matches always take precedence over the fallback.
+func WithCaseFallback() Option {
+	return func(c *Classifier) {
+		c.folded = make(map[string]map[string]bool)
+	}
+}
+
+// WithStrictTokenizer makes loading a model fail with ErrTokenizerMismatch
+// when it was saved with a differently configured tokenizer, rather than only
+// logging a warning to the Logger, if any
+func WithStrictTokenizer() Option {
+	return func(c *Classifier) {
+		c.strict = true
+	}
+}
+
+// WithUniqueInputTokens reduces the document being classified to its set of
+// unique tokens before scoring, so a word repeated many times has the same
+// influence as a single occurrence. Training still counts every occurrence.
+func WithUniqueInputTokens() Option {
+	return func(c *Classifier) {
+		c.unique = true
+	}
+}
+
+// WithConfidenceHistogram accumulates the normalized confidence of the top
+// category of every Probabilities call into the given number of equally wide
+// buckets, which can be read back with ConfidenceHistogram to monitor
+// calibration. It has no effect for fewer than one bucket.
+func WithConfidenceHistogram(buckets int) Option {
+	return func(c *Classifier) {
+		if buckets > 0 {
+			c.histogram = make([]int, buckets)
+		}
+	}
+}
+
+// priorMode selects how category priors enter the score
+type priorMode int
+
+const (
+	empiricalPrior priorMode = iota
+	uniformPrior
+	noPrior
+)
+
+// WithUniformPrior gives every category the same prior probability instead of
+// its share of the training documents
+func WithUniformPrior() Option {
+	return func(c *Classifier) {
+		c.prior = uniformPrior
+	}
+}
+
+// WithoutPrior ranks categories purely on the word likelihood by skipping the
+// prior term altogether. Normalized results are the same as with
+// WithUniformPrior, since a uniform prior scales every category equally, but
+// the prior is never computed.
+func WithoutPrior() Option {
+	return func(c *Classifier) {
+		c.prior = noPrior
+	}
+}
+
+// WithPositionalDecay weights the token at position i of a classified
+// document by factor^i, so earlier tokens, e.g. in titles and headlines,
+// carry more signal than later ones. A factor of 1 is the neutral default and
+// leaves every token with full weight.
+func WithPositionalDecay(factor float64) Option {
+	return func(c *Classifier) {
+		c.decay = factor
+	}
+}
+
+// WithClassificationHook calls hook after every Probabilities and Predict
+// call with the classified input, the top category and its normalized
+// confidence, e.g. to keep an audit log. The label is empty and the
+// confidence 0 when no category matched. The hook runs after the classifier's
+// lock is released, so it may call back into the classifier, and it must be
+// safe for concurrent use when the classifier is.
+func WithClassificationHook(hook func(input string, label string, confidence float64)) Option {
+	return func(c *Classifier) {
+		c.hook = hook
+	}
+}
+
+// WithEarlyStopConfidence makes Classify, ClassifyString and Predict score the
+// document token by token and stop as soon as one category's normalized
+// running posterior exceeds threshold, returning that category without
+// reading further evidence. This is an approximation: the remaining tokens
+// could still have changed the outcome. Tokens unknown to the model are
+// skipped rather than scored. It has no effect together with
+// WithNGramMixture, whose features need the whole document.
+func WithEarlyStopConfidence(threshold float64) Option {
+	return func(c *Classifier) {
+		c.earlyStop = threshold
+	}
+}
+
+// WithFeatureHashingSalt stores every token as a hash of the salt and the
+// token instead of the plaintext word, and hashes the tokens of classified
+// documents the same way, so the model can be trained on sensitive text
+// without retaining it. Classification is unaffected but the vocabulary can
+// no longer be inspected: Vocabulary, ExportVocabulary and InfluenceReport
+// only reveal hashes. Models must be loaded with the same
+// salt to be usable, and WithCaseFallback has no effect on hashed words.
+func WithFeatureHashingSalt(salt string) Option {
+	return func(c *Classifier) {
+		c.salt = &salt
+	}
+}
+
+// WithMinTrainingDocs makes Classify, ClassifyString and Predict fail with
+// ErrInsufficientTraining until the model has been trained on at least n
+// documents in total, so a barely trained model never serves predictions
+func WithMinTrainingDocs(n int) Option {
+	return func(c *Classifier) {
+		c.minDocs = n
+	}
+}
+
+// WithNormalizedProbabilities makes Probabilities return normalized linear
+// probabilities that sum to 1, computed from the log-scores with the
+// log-sum-exp trick. Without it the raw scores are exponentiated, which can
+// underflow to zero for long documents.
+func WithNormalizedProbabilities() Option {
+	return func(c *Classifier) {
+		c.normalized = true
+	}
+}
+
+// WithHalfLife decays the contribution of every document trained with TrainAt
+// by its age at classification time, halving its weight every halfLife, so
+// recent trends outweigh old ones. Documents trained without a timestamp keep
+// their full weight. Decay applies to the exact counts only and timestamps
+// are not persisted, so loading or replacing the counts forgets them. It
+// panics unless halfLife is positive.
+func WithHalfLife(halfLife time.Duration) Option {
+	if halfLife <= 0 {
+		panic(fmt.Sprintf("invalid half-life: %v", halfLife))
+	}
+
+	return func(c *Classifier) {
+		c.recency = newRecency(halfLife)
+	}
+}
+
+// WithTFIDF controls whether the contribution of every word to the score is
+// scaled by its inverse document frequency, so words found in many training
+// documents, such as boilerplate, count less than rare discriminative ones.
+// The number of documents containing each word is recorded during training
+// and saved with the model. The weighting only applies to classification.
+func WithTFIDF(enabled bool) Option {
+	return func(c *Classifier) {
+		c.tfidf = enabled
+	}
+}
+
+// WithComplementNB switches to Complement Naive Bayes, which scores every
+// category by how poorly the document matches the combined counts of all
+// other categories instead of how well it matches the category's own counts.
+// Since every complement is estimated from most of the training data, small
+// categories are no longer at a disadvantage to large ones, and the prior is
+// left out of the score for the same reason. Words unknown to the model are
+// ignored, so a document without known words scores every category the same.
+// The resulting scores are not likelihoods, so compare categories with
+// ProbabilitiesNormalized. It needs at least two categories to be meaningful.
+func WithComplementNB() Option {
+	return func(c *Classifier) {
+		c.complement = true
+	}
+}
+
+// UnknownWordStrategy selects how words never seen in training are scored
+type UnknownWordStrategy int
+
+const (
+	// SmoothUnknownWords scores unknown words like any other word, relying on
+	// smoothing to give them a small probability. Without smoothing their
+	// probability is zero and no category can be scored.
+	SmoothUnknownWords UnknownWordStrategy = iota
+	// IgnoreUnknownWords leaves unknown words out of the score entirely, so a
+	// document of only unknown words is scored on the priors alone
+	IgnoreUnknownWords
+	// FloorUnknownWords gives unknown words the same floor probability in
+	// every category: the epsilon configured with WithProbabilityFloor, or
+	// 1e-6. This scales every score by the same factor, so the ranking is
+	// that of IgnoreUnknownWords while Probabilities still shrink with every
+	// unknown word.
+	FloorUnknownWords
+)
+
+// defaultUnknownFloor is the probability of an unknown word under
+// FloorUnknownWords when no probability floor is configured
+const defaultUnknownFloor = 1e-6
+
+// WithUnknownWords selects how words never seen in training are scored; the
+// default is SmoothUnknownWords. Early stopping always ignores unknown words,
+// as does Complement Naive Bayes unless FloorUnknownWords is selected.
+func WithUnknownWords(strategy UnknownWordStrategy) Option {
+	return func(c *Classifier) {
+		c.unknown = strategy
+	}
+}
+
+// unknownFloor returns the probability of an unknown word under
+// FloorUnknownWords
+func (c *Classifier) unknownFloor() float64 {
+	if c.floor > 0 {
+		return c.floor
+	}
+	return defaultUnknownFloor
+}
+
+// WithFallbackCategory sets the category ClassifyWithThreshold returns when
+// the classifier is not confident enough, e.g. "unknown"; the default is ""
+func WithFallbackCategory(category string) Option {
+	return func(c *Classifier) {
+		c.fallback = category
+	}
+}
+
+// WithCompressionLevel sets the gzip level SaveCompressed writes with, from
+// gzip.HuffmanOnly to gzip.BestCompression; the default is
+// gzip.DefaultCompression. It panics for any other level.
+func WithCompressionLevel(level int) Option {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		panic(fmt.Sprintf("invalid compression level: %d", level))
+	}
+
+	return func(c *Classifier) {
+		c.level = level
+	}
+}
@@ -0,0 +1,582 @@
+package naive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/carautenbach/classifier"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	defaults := NewWithOptions()
+	if defaults.Alpha != 1 || defaults.Concurrency != runtime.NumCPU() || defaults.Logger != nil {
+		t.Errorf("Expected the defaults of New; actual: %+v", defaults)
+	}
+
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	tokenizer := classifier.NewTokenizer(classifier.WithLowercase(false))
+	c := NewWithOptions(WithTokenizer(tokenizer), WithAlpha(0.5), WithConcurrency(2), WithLogger(logger))
+	if c.Tokenizer != tokenizer || c.Alpha != 0.5 || c.Concurrency != 2 || c.Logger != logger {
+		t.Errorf("Expected the configured settings; actual: %+v", c)
+	}
+
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("Black shepherd", "Dog")
+	if _, topResult := c.Probabilities("White kitty"); topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+	if _, ok := c.Feat2cat["White"]; !ok {
+		t.Errorf("Expected the configured tokenizer to keep %q", "White")
+	}
+	if buf.Len() == 0 {
+		t.Errorf("Expected the configured logger to receive diagnostics")
+	}
+
+	if NewWithOptions(WithTokenizer(nil)).Tokenizer == nil {
+		t.Errorf("Expected a nil tokenizer to keep the standard tokenizer")
+	}
+
+	c = NewWithOptions(WithStopWords([]string{"kitty"}))
+	c.TrainString("The white kitty", "Cat")
+	if vocabulary := c.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"the", "white"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"the", "white"}, vocabulary)
+	}
+
+	c = NewWithOptions(WithLowercase(false))
+	c.TrainString("White KITTY", "Cat")
+	if vocabulary := c.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"KITTY", "White"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"KITTY", "White"}, vocabulary)
+	}
+}
+
+func TestWithAlphaInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for a negative alpha")
+		}
+	}()
+	WithAlpha(-1)
+}
+
+func TestWithProbabilityFloor(t *testing.T) {
+	train := func(c *Classifier) {
+		c.SetAlpha(0)
+		c.TrainString("German shepherd", "Dog")
+		c.TrainString("White pointer", "Dog")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White kitty", "Cat")
+	}
+
+	legacy := New()
+	train(legacy)
+	if probabilities, _ := legacy.Probabilities("White shepherd kitty"); len(probabilities) != 0 {
+		t.Errorf("Expected every category to score zero; actual: %v", probabilities)
+	}
+
+	floored := New(WithProbabilityFloor(1e-3))
+	train(floored)
+	probabilities, topResult := floored.Probabilities("White shepherd kitty")
+	for _, category := range []string{"Dog", "Cat"} {
+		if probabilities[category] <= 0 {
+			t.Errorf("Expected a positive %s probability; actual: %f", category, probabilities[category])
+		}
+	}
+	if topResult == "" {
+		t.Errorf("Expected a top category")
+	}
+}
+
+func TestWithDisplayFloor(t *testing.T) {
+	train := func(c *Classifier) {
+		c.TrainString("German shepherd", "Dog")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("Guppy", "Fish")
+	}
+
+	// the other categories fall so far behind on a long document that their
+	// normalized probabilities round to zero
+	input := strings.Repeat("kitty ", 1000)
+
+	plain := New()
+	train(plain)
+	if probabilities, _ := plain.ProbabilitiesNormalized(input); probabilities["Dog"] != 0 || probabilities["Fish"] != 0 {
+		t.Errorf("Expected only Cat without a floor; actual: %v", probabilities)
+	}
+
+	floored := New(WithDisplayFloor(1e-4))
+	train(floored)
+	probabilities, topResult := floored.ProbabilitiesNormalized(input)
+	if topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+	if len(probabilities) != 3 {
+		t.Errorf("Expected every category; actual: %v", probabilities)
+	}
+
+	sum := 0.0
+	for category, p := range probabilities {
+		if p == 0 {
+			t.Errorf("Expected a non-zero probability for %s", category)
+		}
+		if category != "Cat" && p >= probabilities["Cat"] {
+			t.Errorf("Expected %s to remain below Cat; actual: %f", category, p)
+		}
+		sum += p
+	}
+	assertFloat(t, "sum", 1, sum)
+}
+
+func TestWithAdaptiveSmoothing(t *testing.T) {
+	classifier := New(WithAdaptiveSmoothing())
+
+	classifier.TrainString("Black kitty", "Cat")
+	for i := 0; i < 20; i++ {
+		classifier.TrainString("German shepherd", "Dog")
+		classifier.TrainString("White pointer", "Dog")
+	}
+
+	uniform := 1 / float64(len(classifier.Feat2cat))
+	shrinkage := func(word, category string) float64 {
+		mle := classifier.countOfWordInCategory(word, category) / classifier.totalCountInCategory(category)
+		smoothed := classifier.probabilityOfWordInCategory(word, category)
+		return (smoothed - mle) / (uniform - mle)
+	}
+
+	small := shrinkage("kitty", "Cat")
+	large := shrinkage("shepherd", "Dog")
+	if small <= large {
+		t.Errorf("Expected the small category to be pulled harder towards uniform; actual: %f <= %f", small, large)
+	}
+
+	if p := classifier.probabilityOfWordInCategory("kitty", "Dog"); p <= 0 {
+		t.Errorf("Expected a positive probability for an unseen word; actual: %f", p)
+	}
+}
+
+func TestWithNGramMixture(t *testing.T) {
+	train := func(c *Classifier) {
+		for i := 0; i < 2; i++ {
+			c.TrainString("Hot dog", "Food")
+			c.TrainString("Hot dog", "Food")
+			c.TrainString("Dog is hot", "Pets")
+			c.TrainString("Dog is hot", "Pets")
+			c.TrainString("Dog is hot", "Pets")
+		}
+	}
+
+	unigrams := New()
+	train(unigrams)
+	if _, topResult := unigrams.Probabilities("Hot dog"); topResult != "Pets" {
+		t.Errorf("Expected unigrams alone to pick %s; actual: %s", "Pets", topResult)
+	}
+
+	mixture := New(WithNGramMixture(map[int]float64{1: 0.5, 2: 0.5}))
+	train(mixture)
+	if _, ok := mixture.Feat2cat["hot_dog"]; !ok {
+		t.Errorf("Expected a bigram feature in the model")
+	}
+	if _, topResult := mixture.Probabilities("Hot dog"); topResult != "Food" {
+		t.Errorf("Expected %s; actual: %s", "Food", topResult)
+	}
+}
+
+func TestWithNGramMixtureInvalidOrder(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for an invalid order")
+		}
+	}()
+	WithNGramMixture(map[int]float64{0: 1})
+}
+
+func TestWithCaseFallback(t *testing.T) {
+	train := func(c *Classifier) {
+		c.Tokenizer = classifier.NewTokenizer(classifier.Transforms())
+		c.TrainString("HTTP request", "Web")
+		c.TrainString("http client", "Code")
+		c.TrainString("SQL query", "Database")
+	}
+
+	exact := New()
+	train(exact)
+	if _, topResult := exact.Probabilities("HTTP"); topResult != "Web" {
+		t.Errorf("Expected %s; actual: %s", "Web", topResult)
+	}
+	if _, usedVocabulary := exact.ClassifyExplained("Http"); usedVocabulary {
+		t.Errorf("Expected no match without fallback")
+	}
+
+	fallback := New(WithCaseFallback())
+	train(fallback)
+
+	tests := []struct {
+		Input    string
+		Expected string
+	}{
+		{"HTTP", "Web"},
+		{"http", "Code"},
+		{"sql", "Database"},
+	}
+	for _, test := range tests {
+		if _, topResult := fallback.Probabilities(test.Input); topResult != test.Expected {
+			t.Errorf("%s: expected %s; actual: %s", test.Input, test.Expected, topResult)
+		}
+	}
+
+	probabilities, _ := fallback.Probabilities("Http")
+	if probabilities["Web"] != probabilities["Code"] || probabilities["Web"] <= probabilities["Database"] {
+		t.Errorf("Expected both case variants to match equally; actual: %v", probabilities)
+	}
+}
+
+func TestWithUniqueInputTokens(t *testing.T) {
+	train := func(c *Classifier) {
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White shepherd", "Dog")
+		c.TrainString("White kitty pointer", "Dog")
+	}
+	repeated := "white" + strings.Repeat(" kitty", 10)
+
+	plain := New()
+	train(plain)
+	once, _ := plain.Probabilities("white kitty")
+	many, _ := plain.Probabilities(repeated)
+	if once["Cat"] == many["Cat"] {
+		t.Errorf("Expected repeated words to change the score without the option")
+	}
+
+	unique := New(WithUniqueInputTokens())
+	train(unique)
+	once, _ = unique.Probabilities("white kitty")
+	many, _ = unique.Probabilities(repeated)
+	for category, p := range once {
+		if many[category] != p {
+			t.Errorf("Expected %s probability %f; actual: %f", category, p, many[category])
+		}
+	}
+	if plain.Feat2cat["kitty"]["Cat"] != unique.Feat2cat["kitty"]["Cat"] {
+		t.Errorf("Expected training to be unaffected")
+	}
+}
+
+func TestWithoutPrior(t *testing.T) {
+	train := func(c *Classifier) {
+		for i := 0; i < 8; i++ {
+			c.TrainString("Fluffy puppy", "Dog")
+		}
+		c.TrainString("Fluffy kitty", "Cat")
+		c.TrainString("Fluffy kitty", "Cat")
+	}
+
+	empirical, uniform, without := New(), New(WithUniformPrior()), New(WithoutPrior())
+	for _, c := range []*Classifier{empirical, uniform, without} {
+		train(c)
+	}
+
+	if _, topResult := empirical.Probabilities("Fluffy"); topResult != "Dog" {
+		t.Errorf("Expected the empirical prior to pick %s; actual: %s", "Dog", topResult)
+	}
+
+	expected, _ := uniform.ProbabilitiesNormalized("Fluffy")
+	actual, _ := without.ProbabilitiesNormalized("Fluffy")
+	for category, p := range expected {
+		assertFloat(t, category, p, actual[category])
+	}
+	// without a prior only the smoothed likelihoods of fluffy remain: 9/11 for
+	// Dog and 3/5 for Cat
+	assertFloat(t, "Dog", (9.0/11)/(9.0/11+3.0/5), actual["Dog"])
+}
+
+func TestWithPositionalDecay(t *testing.T) {
+	train := func(c *Classifier) {
+		c.TrainString("Kitty", "Cat")
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("White", "Dog")
+		c.TrainString("White shepherd", "Dog")
+	}
+	front := "kitty white shepherd"
+	back := "white shepherd kitty"
+
+	plain := New(WithProbabilityFloor(0.1))
+	train(plain)
+	frontScores, _ := plain.ProbabilitiesNormalized(front)
+	backScores, _ := plain.ProbabilitiesNormalized(back)
+	assertFloat(t, "Cat", frontScores["Cat"], backScores["Cat"])
+
+	decayed := New(WithProbabilityFloor(0.1), WithPositionalDecay(0.5))
+	train(decayed)
+	frontScores, _ = decayed.ProbabilitiesNormalized(front)
+	backScores, _ = decayed.ProbabilitiesNormalized(back)
+	if frontScores["Cat"] <= backScores["Cat"] {
+		t.Errorf("Expected kitty to count more at the front; actual: %f <= %f", frontScores["Cat"], backScores["Cat"])
+	}
+}
+
+func TestWithClassificationHook(t *testing.T) {
+	type observation struct {
+		input string
+		label string
+	}
+
+	var observed []observation
+	var c *Classifier
+	c = New(WithClassificationHook(func(input, label string, confidence float64) {
+		// re-entering the classifier must not deadlock
+		c.ProbabilitiesNormalized(input)
+		if label != "" && (confidence <= 0 || confidence > 1) {
+			t.Errorf("%s: unexpected confidence %f", input, confidence)
+		}
+		observed = append(observed, observation{input, label})
+	}))
+
+	c.TrainString("Fluffy kitty", "Cat")
+	c.TrainString("German shepherd", "Dog")
+
+	c.Probabilities("Kitty")
+	c.Predict("Shepherd")
+	c.Predict("Unknown")
+
+	// an unknown word is smoothed equally in both categories, so the tie is
+	// broken by name
+	expected := []observation{{"Kitty", "Cat"}, {"Shepherd", "Dog"}, {"Unknown", "Cat"}}
+	if !reflect.DeepEqual(expected, observed) {
+		t.Errorf("Expected %v; actual: %v", expected, observed)
+	}
+}
+
+func TestWithMinTrainingDocs(t *testing.T) {
+	c := New(WithMinTrainingDocs(3))
+
+	c.TrainString("Fluffy kitty", "Cat")
+	c.TrainString("German shepherd", "Dog")
+	if actual := c.TotalDocumentCount(); actual != 2 {
+		t.Errorf("Expected %v; actual: %v", 2, actual)
+	}
+	if _, err := c.ClassifyString("Kitty"); !errors.Is(err, ErrInsufficientTraining) {
+		t.Errorf("Expected %v; actual: %v", ErrInsufficientTraining, err)
+	}
+	if _, err := c.Predict("Kitty"); !errors.Is(err, ErrInsufficientTraining) {
+		t.Errorf("Expected %v; actual: %v", ErrInsufficientTraining, err)
+	}
+
+	c.TrainString("White kitty", "Cat")
+	if label, err := c.ClassifyString("Kitty"); err != nil || label != "Cat" {
+		t.Errorf("Expected Cat; actual: %s, %v", label, err)
+	}
+	if label, err := c.Predict("Kitty"); err != nil || label != "Cat" {
+		t.Errorf("Expected Cat; actual: %s, %v", label, err)
+	}
+}
+
+func TestWithNormalizedProbabilities(t *testing.T) {
+	train := func(c *Classifier) {
+		c.TrainString("Fluffy white kitty meows", "Cat")
+		c.TrainString("Black kitty purrs", "Cat")
+		c.TrainString("German shepherd barks", "Dog")
+		c.TrainString("White pointer wags", "Dog")
+	}
+
+	// a 200 word document whose scores underflow when multiplied out
+	input := strings.Repeat("kitty shepherd kitty meows ", 50)
+
+	raw := New()
+	train(raw)
+	probabilities, topResult := raw.Probabilities(input)
+	if topResult != "Cat" || probabilities["Dog"] <= 0 || probabilities["Cat"] <= probabilities["Dog"] {
+		t.Errorf("Expected non-zero scores with Cat on top; actual: %v, %s", probabilities, topResult)
+	}
+	for category, score := range raw.LogScores(input) {
+		assertFloat(t, category, score, math.Log(probabilities[category]))
+	}
+
+	normalized := New(WithNormalizedProbabilities())
+	train(normalized)
+	probabilities, topResult = normalized.Probabilities(input)
+	if topResult != "Cat" {
+		t.Errorf("Expected %s; actual: %s", "Cat", topResult)
+	}
+	if probabilities["Dog"] <= 0 || probabilities["Cat"] <= probabilities["Dog"] {
+		t.Errorf("Expected non-zero scores with Cat above Dog; actual: %v", probabilities)
+	}
+	assertFloat(t, "sum", 1, probabilities["Cat"]+probabilities["Dog"])
+}
+
+func TestWithTFIDF(t *testing.T) {
+	boilerplate := "newsletter subscribe unsubscribe privacy"
+	train := []Example{
+		{Text: "kitty meow " + boilerplate + " " + boilerplate + " " + boilerplate, Category: "Cat"},
+		{Text: "kitten purr " + boilerplate + " " + boilerplate + " " + boilerplate, Category: "Cat"},
+		{Text: "kitty purr " + boilerplate + " " + boilerplate + " " + boilerplate, Category: "Cat"},
+		{Text: "puppy bark " + boilerplate, Category: "Dog"},
+		{Text: "hound woof " + boilerplate, Category: "Dog"},
+		{Text: "puppy woof " + boilerplate, Category: "Dog"},
+	}
+	test := []Example{
+		{Text: "puppy " + boilerplate + " " + boilerplate, Category: "Dog"},
+		{Text: "hound bark " + boilerplate, Category: "Dog"},
+		{Text: "woof " + boilerplate, Category: "Dog"},
+		{Text: "kitty " + boilerplate, Category: "Cat"},
+		{Text: "meow purr", Category: "Cat"},
+	}
+
+	accuracy := func(c *Classifier) float64 {
+		if err := c.TrainBatch(train); err != nil {
+			t.Fatal(err)
+		}
+		correct := 0
+		for _, example := range test {
+			if _, label := c.ProbabilitiesNormalized(example.Text); label == example.Category {
+				correct++
+			}
+		}
+		return float64(correct) / float64(len(test))
+	}
+
+	plain, weighted := accuracy(New()), accuracy(New(WithTFIDF(true)))
+	if weighted <= plain {
+		t.Errorf("Expected TF-IDF to improve accuracy: %v <= %v", weighted, plain)
+	}
+	if weighted != 1 {
+		t.Errorf("Expected %v; actual: %v", 1.0, weighted)
+	}
+
+	c := New(WithTFIDF(true))
+	c.TrainBatch(train)
+	known, _ := c.ProbabilitiesNormalized("puppy")
+	unknown, _ := c.ProbabilitiesNormalized("puppy zebra")
+	if math.Abs(known["Dog"]-unknown["Dog"]) > 1e-9 {
+		t.Errorf("Expected unknown words to carry no weight: %v != %v", known, unknown)
+	}
+}
+
+func TestIDFDocumentFrequency(t *testing.T) {
+	c := New(WithTFIDF(true))
+	c.TrainString("kitty kitty kitty kitty kitty", "Cat")
+	c.TrainString("meow", "Cat")
+	c.TrainString("puppy", "Dog")
+	c.TrainString("hound", "Dog")
+	assertFloat(t, "repeated word", math.Log(4.0/2), c.idf("kitty"))
+
+	c.TrainString("kitty purr", "Cat")
+	assertFloat(t, "second document", math.Log(5.0/3), c.idf("kitty"))
+	if err := c.UntrainString("kitty purr", "Cat"); err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "untrained", math.Log(4.0/2), c.idf("kitty"))
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "loaded", math.Log(4.0/2), loaded.idf("kitty"))
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmarshaled := New()
+	if err := json.Unmarshal(data, unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "unmarshaled", math.Log(4.0/2), unmarshaled.idf("kitty"))
+
+	legacy := New()
+	if err := json.Unmarshal([]byte(`{"version": 1, "feat2cat": {"kitty": {"Cat": 5}, "puppy": {"Dog": 1}}, "catCount": {"Cat": 2, "Dog": 2}}`), legacy); err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "estimated", 0, legacy.idf("kitty"))
+	assertFloat(t, "estimated", math.Log(4.0/2), legacy.idf("puppy"))
+}
+
+func TestWithComplementNB(t *testing.T) {
+	standard, complement := New(), New(WithComplementNB())
+	for _, c := range []*Classifier{standard, complement} {
+		for i := 0; i < 12; i++ {
+			c.TrainString("sunny warm match", "Yes")
+		}
+		c.TrainString("rainy match", "No")
+		c.TrainString("rainy cold", "No")
+	}
+
+	biased, topResult := standard.ProbabilitiesNormalized("rainy match")
+	if topResult != "Yes" {
+		t.Errorf("Expected %s; actual: %s", "Yes", topResult)
+	}
+	balanced, topResult := complement.ProbabilitiesNormalized("rainy match")
+	if topResult != "No" {
+		t.Errorf("Expected %s; actual: %s", "No", topResult)
+	}
+	if balanced["No"] <= biased["No"] {
+		t.Errorf("Expected the complement to favor the minority more: %v <= %v", balanced["No"], biased["No"])
+	}
+
+	if _, topResult := complement.ProbabilitiesNormalized("sunny"); topResult != "Yes" {
+		t.Errorf("Expected %s; actual: %s", "Yes", topResult)
+	}
+
+	if unknown, _ := complement.ProbabilitiesNormalized("unknown"); math.Abs(unknown["Yes"]-unknown["No"]) > 1e-9 {
+		t.Errorf("Expected unknown words to score every category the same; actual: %v", unknown)
+	}
+}
+
+func TestWithUnknownWords(t *testing.T) {
+	train := func(c *Classifier) *Classifier {
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("German shepherd", "Dog")
+		return c
+	}
+	priors := map[string]float64{"Cat": 2.0 / 3, "Dog": 1.0 / 3}
+	input := "zebra giraffe"
+
+	// without smoothing an unknown word has probability zero everywhere
+	if probabilities, _ := train(New(WithAlpha(0))).Probabilities(input); len(probabilities) != 0 {
+		t.Errorf("Expected no scores; actual: %v", probabilities)
+	}
+
+	for _, opts := range [][]Option{
+		{WithUnknownWords(SmoothUnknownWords)},
+		{WithUnknownWords(IgnoreUnknownWords)},
+		{WithUnknownWords(IgnoreUnknownWords), WithAlpha(0)},
+		{WithUnknownWords(FloorUnknownWords)},
+		{WithUnknownWords(FloorUnknownWords), WithAlpha(0)},
+	} {
+		probabilities, topResult := train(New(opts...)).Probabilities(input)
+		if topResult != "Cat" || len(probabilities) != 2 {
+			t.Errorf("Expected both categories with %s on top; actual: %v", "Cat", probabilities)
+		}
+		for category, p := range probabilities {
+			if math.IsNaN(p) || p <= 0 {
+				t.Errorf("Expected a positive probability for %s; actual: %v", category, p)
+			}
+		}
+	}
+
+	ignored, _ := train(New(WithUnknownWords(IgnoreUnknownWords), WithAlpha(0))).Probabilities(input)
+	for category, prior := range priors {
+		if math.Abs(ignored[category]-prior) > 1e-9 {
+			t.Errorf("Expected the prior %v for %s; actual: %v", prior, category, ignored[category])
+		}
+	}
+
+	floored, _ := train(New(WithUnknownWords(FloorUnknownWords), WithAlpha(0))).Probabilities(input)
+	for category, prior := range priors {
+		if expected := prior * defaultUnknownFloor * defaultUnknownFloor; math.Abs(floored[category]-expected) > 1e-20 {
+			t.Errorf("Expected %v for %s; actual: %v", expected, category, floored[category])
+		}
+	}
+}
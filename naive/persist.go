@@ -0,0 +1,155 @@
+package naive
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/carautenbach/classifier"
+)
+
+// ErrTokenizerMismatch is returned when loading a model that was saved with a
+// differently configured tokenizer while WithStrictTokenizer is set
+var ErrTokenizerMismatch = errors.New("tokenizer signature mismatch")
+
+// ErrUnsupportedVersion is returned when loading a model written in a format
+// version other than FormatVersion
+var ErrUnsupportedVersion = errors.New("unsupported model format version")
+
+// FormatVersion is the version of the format written by Save, which Load
+// requires. It changes whenever models written by an older version could no
+// longer be read correctly.
+const FormatVersion = 1
+
+// tokenizerSignature describes the configuration of the tokenizer so that
+// models can detect being loaded into an incompatible feature space.
+// Tokenizers that do not implement classifier.Signer are identified by type.
+func tokenizerSignature(t classifier.Tokenizer) string {
+	if s, ok := t.(classifier.Signer); ok {
+		return s.Signature()
+	}
+	return fmt.Sprintf("%T", t)
+}
+
+// checkSignature compares the signature stored with a model against the
+// current tokenizer, logging a warning to the classifier's Logger, if any, on
+// mismatch or failing under strict mode
+func (c *Classifier) checkSignature(stored string) error {
+	current := tokenizerSignature(c.Tokenizer)
+	if stored == current {
+		return nil
+	}
+	if c.strict {
+		return fmt.Errorf("%w: saved with %s, loading with %s", ErrTokenizerMismatch, stored, current)
+	}
+	if c.Logger != nil {
+		c.Logger.Printf("naive: model was saved with tokenizer %s but is loaded with %s", stored, current)
+	}
+	return nil
+}
+
+// Save writes the model's counts to w with encoding/gob. The counts are
+// copied under the read lock, so the model can be saved while it is queried.
+func (c *Classifier) Save(w io.Writer) error {
+	return c.encode(w)
+}
+
+// Load reads a model written by Save into a new classifier configured with
+// opts. Models of a format version other than FormatVersion, including ones
+// written before the version was recorded, fail with ErrUnsupportedVersion.
+// The tokenizer is not serialized; the standard tokenizer is used
+// unless an option replaces it, and is checked against the one the model was
+// saved with.
+func Load(r io.Reader, opts ...Option) (*Classifier, error) {
+	c := New(opts...)
+	if err := c.decode(r); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SaveCompressed behaves like Save but compresses the output with gzip. The
+// optional level, from gzip.HuffmanOnly to gzip.BestCompression, overrides
+// the one configured with WithCompressionLevel; any other level, or more
+// than one, is an error.
+func (c *Classifier) SaveCompressed(w io.Writer, level ...int) error {
+	lvl := c.level
+	switch len(level) {
+	case 0:
+	case 1:
+		lvl = level[0]
+	default:
+		return fmt.Errorf("expected at most one compression level; got %d", len(level))
+	}
+	zw, err := gzip.NewWriterLevel(w, lvl)
+	if err != nil {
+		return err
+	}
+	if err := c.encode(zw); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// LoadCompressed reads a model written by SaveCompressed into a new classifier
+// configured with opts, like Load
+func LoadCompressed(r io.Reader, opts ...Option) (*Classifier, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return Load(zr, opts...)
+}
+
+// model is the gob encoded form of a Classifier
+type model struct {
+	FormatVersion int
+	Signature     string
+	Feat2cat      map[string]map[string]float64
+	CatCount      map[string]float64
+	// DocFreq is absent from models saved before it was recorded
+	DocFreq map[string]float64
+}
+
+// encode writes a snapshot of the model's counts with encoding/gob
+func (c *Classifier) encode(w io.Writer) error {
+	snapshot, err := c.Snapshot()
+	if err != nil {
+		return err
+	}
+	_, err = snapshot.WriteTo(w)
+	return err
+}
+
+// decode replaces the model's counts with ones written by encode, keeping the
+// configured tokenizer
+func (c *Classifier) decode(r io.Reader) error {
+	var m model
+	if err := gob.NewDecoder(r).Decode(&m); err != nil {
+		return err
+	}
+	if m.FormatVersion != FormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, m.FormatVersion)
+	}
+	if err := c.checkSignature(m.Signature); err != nil {
+		return err
+	}
+	if m.Feat2cat == nil {
+		m.Feat2cat = make(map[string]map[string]float64)
+	}
+	if m.CatCount == nil {
+		m.CatCount = make(map[string]float64)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Feat2cat = m.Feat2cat
+	c.CatCount = m.CatCount
+	c.docFreq = m.DocFreq
+	c.reindex()
+	return nil
+}
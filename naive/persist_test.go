@@ -0,0 +1,144 @@
+package naive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/carautenbach/classifier"
+)
+
+func TestSaveLoad(t *testing.T) {
+	trained := New()
+	trained.TrainString("The quick brown fox jumps", "Animal")
+	trained.TrainString("The lazy dog sleeps", "Animal")
+	trained.TrainString("Stocks rally as markets open", "Finance")
+
+	var buf bytes.Buffer
+	if err := trained.Save(&buf); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("unable to load: %v", err)
+	}
+
+	for _, input := range []string{"quick dog", "markets rally", "unseen words"} {
+		expected, expectedTop := trained.Probabilities(input)
+		actual, actualTop := loaded.Probabilities(input)
+		if expectedTop != actualTop {
+			t.Errorf("Expected %v; actual: %v", expectedTop, actualTop)
+		}
+		for category, p := range expected {
+			if actual[category] != p {
+				t.Errorf("Expected %v; actual: %v", p, actual[category])
+			}
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	if _, err := Load(bytes.NewReader([]byte("not a model"))); err == nil {
+		t.Error("Expected an error loading garbage")
+	}
+
+	var buf bytes.Buffer
+	trained := New()
+	trained.TrainString("Kitty", "Cat")
+	if err := trained.Save(&buf); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+	filtered := func(c *Classifier) { c.Tokenizer = classifier.NewTokenizer(classifier.Filters()) }
+	_, err := Load(&buf, filtered, WithStrictTokenizer())
+	if !errors.Is(err, ErrTokenizerMismatch) {
+		t.Errorf("Expected %v; actual: %v", ErrTokenizerMismatch, err)
+	}
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	for _, version := range []int{0, FormatVersion + 1} {
+		var buf bytes.Buffer
+		m := model{
+			FormatVersion: version,
+			Signature:     tokenizerSignature(classifier.NewTokenizer()),
+			Feat2cat:      map[string]map[string]float64{"kitty": {"Cat": 1}},
+			CatCount:      map[string]float64{"Cat": 1},
+		}
+		if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(&buf); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("Expected %v for version %d; actual: %v", ErrUnsupportedVersion, version, err)
+		}
+	}
+}
+
+func TestSaveLoadCompressed(t *testing.T) {
+	trained := New(WithCompressionLevel(gzip.BestCompression))
+	for i := 0; i < 200; i++ {
+		trained.TrainString(fmt.Sprintf("kitty number %d purrs and meows", i), "Cat")
+		trained.TrainString(fmt.Sprintf("puppy number %d barks and wags", i), "Dog")
+	}
+
+	var raw, compressed bytes.Buffer
+	if err := trained.Save(&raw); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+	if err := trained.SaveCompressed(&compressed); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+	if magic := compressed.Bytes()[:2]; magic[0] != 0x1f || magic[1] != 0x8b {
+		t.Errorf("Expected the gzip magic bytes; actual: %x", magic)
+	}
+	if compressed.Len()*2 > raw.Len() {
+		t.Errorf("Expected the compressed model to be at most half the size: %d > %d / 2", compressed.Len(), raw.Len())
+	}
+
+	loaded, err := LoadCompressed(&compressed)
+	if err != nil {
+		t.Fatalf("unable to load: %v", err)
+	}
+	for _, input := range []string{"kitty 7", "puppy barks", "unseen words"} {
+		expected, expectedTop := trained.Probabilities(input)
+		actual, actualTop := loaded.Probabilities(input)
+		if expectedTop != actualTop || !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Expected %v %v; actual: %v %v", expectedTop, expected, actualTop, actual)
+		}
+	}
+
+	if _, err := LoadCompressed(&raw); err == nil {
+		t.Error("Expected an error loading an uncompressed model")
+	}
+}
+
+func TestSaveCompressedLevel(t *testing.T) {
+	c := New()
+	c.TrainString("kitty purrs and meows", "Cat")
+
+	var buf bytes.Buffer
+	if err := c.SaveCompressed(&buf, gzip.BestSpeed); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+	if _, err := LoadCompressed(&buf); err != nil {
+		t.Errorf("unable to load: %v", err)
+	}
+	if err := c.SaveCompressed(&buf, 10); err == nil {
+		t.Error("Expected an error for an invalid level")
+	}
+	if err := c.SaveCompressed(&buf, gzip.BestSpeed, gzip.BestCompression); err == nil {
+		t.Error("Expected an error for more than one level")
+	}
+}
+
+func TestWithCompressionLevelInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for an invalid level")
+		}
+	}()
+	WithCompressionLevel(10)
+}
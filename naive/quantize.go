@@ -0,0 +1,265 @@
+package naive
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+const quantizedMagic = "NBQ2"
+
+// ErrInvalidFormat is returned when loading data that was not produced by the
+// corresponding save method
+var ErrInvalidFormat = errors.New("invalid model format")
+
+// SaveQuantized writes the model with every feature count scaled and rounded
+// to an unsigned integer of the given width. Supported widths are 8 and 16
+// bits; 32 bits stores whole counts exactly and serves as the lossless
+// baseline for models trained without fractional weights.
+//
+// Quantization trades accuracy for size: counts are divided by a common scale
+// (the largest count over the largest representable value) and rounded, so a
+// count may be off by up to half the scale after loading, and non-zero counts
+// never round down to zero. Models with whole counts whose largest count fits
+// in the chosen width are stored exactly. Document counts are always stored
+// exactly.
+func (c *Classifier) SaveQuantized(w io.Writer, bits int) error {
+	if bits != 8 && bits != 16 && bits != 32 {
+		return fmt.Errorf("unsupported quantization width: %d", bits)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories := c.sortedCategories()
+	index := make(map[string]int, len(categories))
+	for i, category := range categories {
+		index[category] = i
+	}
+
+	largest := 0.0
+	for _, counts := range c.Feat2cat {
+		for _, count := range counts {
+			if count > largest {
+				largest = count
+			}
+		}
+	}
+
+	levels := float64(uint64(1)<<uint(bits) - 1)
+	scale := 1.0
+	if largest > levels {
+		scale = largest / levels
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(quantizedMagic)
+	bw.WriteByte(byte(bits))
+	binary.Write(bw, binary.LittleEndian, scale)
+	writeString(bw, tokenizerSignature(c.Tokenizer))
+
+	writeUvarint(bw, uint64(len(categories)))
+	for _, category := range categories {
+		writeString(bw, category)
+		binary.Write(bw, binary.LittleEndian, c.CatCount[category])
+	}
+
+	words := make([]string, 0, len(c.Feat2cat))
+	for word := range c.Feat2cat {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	writeUvarint(bw, uint64(len(words)))
+	value := make([]byte, bits/8)
+	for _, word := range words {
+		counts := c.Feat2cat[word]
+		writeString(bw, word)
+		writeUvarint(bw, uint64(len(counts)))
+		for _, category := range sortedKeys(counts) {
+			q := uint64(math.Round(counts[category] / scale))
+			if q == 0 {
+				q = 1
+			}
+			putUint(value, q)
+			writeUvarint(bw, uint64(index[category]))
+			bw.Write(value)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// LoadQuantized replaces the model with one written by SaveQuantized. The
+// configured tokenizer is kept and checked against the one the model was
+// saved with.
+func (c *Classifier) LoadQuantized(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(quantizedMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return err
+	}
+	if string(magic) != quantizedMagic {
+		return ErrInvalidFormat
+	}
+
+	bits, err := br.ReadByte()
+	if err != nil {
+		return err
+	}
+	if bits != 8 && bits != 16 && bits != 32 {
+		return fmt.Errorf("unsupported quantization width: %d", bits)
+	}
+
+	var scale float64
+	if err := binary.Read(br, binary.LittleEndian, &scale); err != nil {
+		return err
+	}
+
+	signature, err := readString(br)
+	if err != nil {
+		return err
+	}
+	if err := c.checkSignature(signature); err != nil {
+		return err
+	}
+
+	numCategories, err := binary.ReadUvarint(br)
+	if err != nil {
+		return err
+	}
+	categories := make([]string, 0, sizeHint(numCategories))
+	catCount := make(map[string]float64, sizeHint(numCategories))
+	for i := uint64(0); i < numCategories; i++ {
+		category, err := readString(br)
+		if err != nil {
+			return err
+		}
+		var count float64
+		if err := binary.Read(br, binary.LittleEndian, &count); err != nil {
+			return err
+		}
+		categories = append(categories, category)
+		catCount[category] = count
+	}
+
+	numWords, err := binary.ReadUvarint(br)
+	if err != nil {
+		return err
+	}
+	feat2cat := make(map[string]map[string]float64, sizeHint(numWords))
+	value := make([]byte, bits/8)
+	for i := uint64(0); i < numWords; i++ {
+		word, err := readString(br)
+		if err != nil {
+			return err
+		}
+		entries, err := binary.ReadUvarint(br)
+		if err != nil {
+			return err
+		}
+		if entries > numCategories {
+			return ErrInvalidFormat
+		}
+		counts := make(map[string]float64, entries)
+		for j := uint64(0); j < entries; j++ {
+			category, err := binary.ReadUvarint(br)
+			if err != nil {
+				return err
+			}
+			if category >= numCategories {
+				return ErrInvalidFormat
+			}
+			if _, err := io.ReadFull(br, value); err != nil {
+				return err
+			}
+			counts[categories[category]] = float64(getUint(value)) * scale
+		}
+		feat2cat[word] = counts
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Feat2cat = feat2cat
+	c.CatCount = catCount
+	c.docFreq = nil
+	c.reindex()
+	return nil
+}
+
+func (c *Classifier) sortedCategories() []string {
+	categories := append([]string(nil), c.getAllCategories()...)
+	sort.Strings(categories)
+	return categories
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func putUint(b []byte, v uint64) {
+	for i := range b {
+		b[i] = byte(v >> (8 * uint(i)))
+	}
+}
+
+func getUint(b []byte) uint64 {
+	var v uint64
+	for i := range b {
+		v |= uint64(b[i]) << (8 * uint(i))
+	}
+	return v
+}
+
+func writeUvarint(w *bufio.Writer, v uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	w.Write(buf[:binary.PutUvarint(buf, v)])
+}
+
+func writeString(w *bufio.Writer, s string) {
+	writeUvarint(w, uint64(len(s)))
+	w.WriteString(s)
+}
+
+// maxSizeHint bounds the capacity preallocated for a number of elements read
+// from a file, so a corrupt count cannot allocate more than the file holds
+const maxSizeHint = 1024
+
+func sizeHint(n uint64) int {
+	if n > maxSizeHint {
+		return maxSizeHint
+	}
+	return int(n)
+}
+
+// readString reads a length-prefixed string. The buffer grows with the bytes
+// actually read rather than the stored length, which may be corrupt.
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > math.MaxInt32 {
+		return "", ErrInvalidFormat
+	}
+	var b strings.Builder
+	if _, err := io.CopyN(&b, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,133 @@
+package naive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/carautenbach/classifier"
+)
+
+func TestSaveQuantized(t *testing.T) {
+	classifier := New()
+	for i := 0; i < 700; i++ {
+		classifier.TrainString("White kitty", "Cat")
+		classifier.TrainString("German shepherd", "Dog")
+	}
+	for i := 0; i < 300; i++ {
+		classifier.TrainString("Black kitty", "Cat")
+		classifier.TrainString("White pointer", "Dog")
+	}
+
+	sizes := make(map[int]int)
+	for _, bits := range []int{8, 16, 32} {
+		var buf bytes.Buffer
+		if err := classifier.SaveQuantized(&buf, bits); err != nil {
+			t.Fatalf("unable to save %d-bit model: %v", bits, err)
+		}
+		sizes[bits] = buf.Len()
+
+		loaded := New()
+		if err := loaded.LoadQuantized(&buf); err != nil {
+			t.Fatalf("unable to load %d-bit model: %v", bits, err)
+		}
+
+		for _, input := range []string{"White kitty", "White shepherd", "Black kitty"} {
+			expected, expectedTop := classifier.Probabilities(input)
+			actual, actualTop := loaded.Probabilities(input)
+			if expectedTop != actualTop {
+				t.Errorf("%d bits: expected %s; actual: %s", bits, expectedTop, actualTop)
+			}
+			for category, p := range expected {
+				if math.Abs(p-actual[category]) > 0.01*p {
+					t.Errorf("%d bits: expected %s probability %f; actual: %f", bits, category, p, actual[category])
+				}
+			}
+		}
+	}
+
+	if sizes[8] >= sizes[16] || sizes[16] >= sizes[32] {
+		t.Errorf("Expected quantized models to shrink; actual sizes: %v", sizes)
+	}
+	t.Logf("8-bit model is %.0f%% of the lossless size", 100*float64(sizes[8])/float64(sizes[32]))
+}
+
+func TestSaveQuantizedInvalidWidth(t *testing.T) {
+	if err := New().SaveQuantized(&bytes.Buffer{}, 12); err == nil {
+		t.Errorf("Expected an error for an unsupported width")
+	}
+	if err := New().LoadQuantized(bytes.NewBufferString("nope")); err != ErrInvalidFormat {
+		t.Errorf("Expected %v; actual: %v", ErrInvalidFormat, err)
+	}
+}
+
+func TestLoadQuantizedTokenizerMismatch(t *testing.T) {
+	saved := New()
+	saved.TrainString("White kitty", "Cat")
+
+	var buf bytes.Buffer
+	if err := saved.SaveQuantized(&buf, 16); err != nil {
+		t.Fatalf("unable to save model: %v", err)
+	}
+	data := buf.Bytes()
+
+	if err := New(WithStrictTokenizer()).LoadQuantized(bytes.NewReader(data)); err != nil {
+		t.Errorf("Expected a matching tokenizer to load; actual: %v", err)
+	}
+
+	strict := New(WithStrictTokenizer())
+	strict.Tokenizer = classifier.NewTokenizer(classifier.Filters())
+	if err := strict.LoadQuantized(bytes.NewReader(data)); !errors.Is(err, ErrTokenizerMismatch) {
+		t.Errorf("Expected %v; actual: %v", ErrTokenizerMismatch, err)
+	}
+	if len(strict.CatCount) != 0 {
+		t.Errorf("Expected a rejected model not to be loaded")
+	}
+
+	var logged bytes.Buffer
+	lenient := New(WithLogger(log.New(&logged, "", 0)))
+	lenient.Tokenizer = classifier.NewTokenizer(classifier.Filters())
+	if err := lenient.LoadQuantized(bytes.NewReader(data)); err != nil {
+		t.Errorf("Expected a mismatch to only warn; actual: %v", err)
+	}
+	if !strings.Contains(logged.String(), "tokenizer") {
+		t.Errorf("Expected a warning to be logged")
+	}
+}
+
+func TestLoadQuantizedCorruptSizes(t *testing.T) {
+	header := func() *bytes.Buffer {
+		var buf bytes.Buffer
+		buf.WriteString(quantizedMagic)
+		buf.WriteByte(8)
+		binary.Write(&buf, binary.LittleEndian, 1.0)
+		signature := tokenizerSignature(classifier.NewTokenizer())
+		buf.Write(uvarint(uint64(len(signature))))
+		buf.WriteString(signature)
+		return &buf
+	}
+
+	// a huge category count followed by nothing
+	categories := header()
+	categories.Write(uvarint(1 << 40))
+	// a single category whose name claims to be huge
+	name := header()
+	name.Write(uvarint(1))
+	name.Write(uvarint(1 << 30))
+	name.WriteString("Cat")
+
+	for _, data := range []*bytes.Buffer{categories, name} {
+		if err := New().LoadQuantized(data); err == nil {
+			t.Error("Expected an error loading a corrupt model")
+		}
+	}
+}
+
+func uvarint(v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutUvarint(buf, v)]
+}
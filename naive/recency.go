@@ -0,0 +1,181 @@
+package naive
+
+import (
+	"io"
+	"math"
+	"time"
+)
+
+// maxRecencyExponent bounds the number of half-lives the accumulated weights
+// may grow past their epoch before they are rebased to avoid overflow
+const maxRecencyExponent = 64
+
+// recency tracks the contributions of timestamped training calls so their
+// counts can be decayed by age. Decayed sums are kept relative to an epoch,
+// with a contribution made at t weighted 2^((t-epoch)/halfLife), so the
+// weight at any later time only needs the common factor
+// 2^(-(now-epoch)/halfLife).
+type recency struct {
+	halfLife time.Duration
+	epoch    time.Time
+	raw      map[string]map[string]float64
+	aged     map[string]map[string]float64
+	rawDocs  map[string]float64
+	agedDocs map[string]float64
+}
+
+func newRecency(halfLife time.Duration) *recency {
+	r := &recency{halfLife: halfLife}
+	r.reset()
+	return r
+}
+
+// reset forgets every timestamped contribution
+func (r *recency) reset() {
+	r.epoch = time.Time{}
+	r.raw = make(map[string]map[string]float64)
+	r.aged = make(map[string]map[string]float64)
+	r.rawDocs = make(map[string]float64)
+	r.agedDocs = make(map[string]float64)
+}
+
+// clone returns a deep copy of the contributions
+func (r *recency) clone() *recency {
+	clone := &recency{
+		halfLife: r.halfLife,
+		epoch:    r.epoch,
+		raw:      make(map[string]map[string]float64, len(r.raw)),
+		aged:     make(map[string]map[string]float64, len(r.aged)),
+		rawDocs:  copyCounts(r.rawDocs),
+		agedDocs: make(map[string]float64, len(r.agedDocs)),
+	}
+	for word, counts := range r.raw {
+		clone.raw[word] = copyCounts(counts)
+	}
+	for word, weights := range r.aged {
+		clone.aged[word] = make(map[string]float64, len(weights))
+		for category, weight := range weights {
+			clone.aged[word][category] = weight
+		}
+	}
+	for category, weight := range r.agedDocs {
+		clone.agedDocs[category] = weight
+	}
+	return clone
+}
+
+// halvings returns the number of half-lives from the epoch to t
+func (r *recency) halvings(t time.Time) float64 {
+	return float64(t.Sub(r.epoch)) / float64(r.halfLife)
+}
+
+// add records a document of the given features trained into the category at
+// t with the given training weight
+func (r *recency) add(features []feature, category string, weight float64, t time.Time) {
+	if r.epoch.IsZero() {
+		r.epoch = t
+	}
+	if exponent := r.halvings(t); exponent > maxRecencyExponent {
+		r.rebase(t)
+	}
+
+	aged := weight * math.Exp2(r.halvings(t))
+	for _, feature := range features {
+		if r.raw[feature.word] == nil {
+			r.raw[feature.word] = make(map[string]float64)
+			r.aged[feature.word] = make(map[string]float64)
+		}
+		r.raw[feature.word][category] += weight
+		r.aged[feature.word][category] += aged
+	}
+	r.rawDocs[category] += weight
+	r.agedDocs[category] += aged
+}
+
+// rebase moves the epoch to t, rescaling the accumulated weights
+func (r *recency) rebase(t time.Time) {
+	scale := math.Exp2(-r.halvings(t))
+	for _, counts := range r.aged {
+		for category := range counts {
+			counts[category] *= scale
+		}
+	}
+	for category := range r.agedDocs {
+		r.agedDocs[category] *= scale
+	}
+	r.epoch = t
+}
+
+// remove forgets the contributions of the word
+func (r *recency) remove(word string) {
+	delete(r.raw, word)
+	delete(r.aged, word)
+}
+
+// limitWord caps the timestamped contributions of the word in the category
+// at count, keeping their average weight
+func (r *recency) limitWord(word, category string, count float64) {
+	raw, ok := r.raw[word][category]
+	if !ok || raw <= count {
+		return
+	}
+	if count == 0 {
+		delete(r.raw[word], category)
+		delete(r.aged[word], category)
+		return
+	}
+	r.aged[word][category] *= count / raw
+	r.raw[word][category] = count
+}
+
+// limitDocs caps the timestamped documents of the category at count, keeping
+// their average weight
+func (r *recency) limitDocs(category string, count float64) {
+	raw, ok := r.rawDocs[category]
+	if !ok || raw <= count {
+		return
+	}
+	if count == 0 {
+		delete(r.rawDocs, category)
+		delete(r.agedDocs, category)
+		return
+	}
+	r.agedDocs[category] *= count / raw
+	r.rawDocs[category] = count
+}
+
+// decay returns the factor turning accumulated weights into weights as of now
+func (r *recency) decay() float64 {
+	if r.epoch.IsZero() {
+		return 0
+	}
+	return math.Exp2(-r.halvings(time.Now()))
+}
+
+// wordAdjustment returns the amount to add to the undecayed count of the word
+// in the category to account for the age of its timestamped contributions
+func (r *recency) wordAdjustment(word, category string) float64 {
+	raw, ok := r.raw[word][category]
+	if !ok {
+		return 0
+	}
+	return r.aged[word][category]*r.decay() - raw
+}
+
+// docAdjustment returns the amount to add to the undecayed document count of
+// the category
+func (r *recency) docAdjustment(category string) float64 {
+	raw, ok := r.rawDocs[category]
+	if !ok {
+		return 0
+	}
+	return r.agedDocs[category]*r.decay() - raw
+}
+
+// TrainAt behaves like Train but records that the document was observed at t.
+// With WithHalfLife configured the document's contribution to the counts
+// decays with its age at classification time; otherwise the timestamp is
+// ignored.
+func (c *Classifier) TrainAt(r io.Reader, category string, t time.Time) error {
+	return c.train(r, category, 1, t)
+}
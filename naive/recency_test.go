@@ -0,0 +1,61 @@
+package naive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrainAt(t *testing.T) {
+	now := time.Now()
+	train := func(c *Classifier) {
+		for i := 0; i < 5; i++ {
+			c.TrainAt(AsReader("Breaking election news"), "Politics", now.Add(-30*24*time.Hour))
+		}
+		c.TrainAt(AsReader("Breaking transfer news"), "Sports", now)
+	}
+
+	timeless := New()
+	train(timeless)
+	if _, topResult := timeless.Probabilities("Breaking news"); topResult != "Politics" {
+		t.Errorf("Expected %s without decay; actual: %s", "Politics", topResult)
+	}
+
+	decayed := New(WithHalfLife(24 * time.Hour))
+	train(decayed)
+	if _, topResult := decayed.Probabilities("Breaking news"); topResult != "Sports" {
+		t.Errorf("Expected %s with decay; actual: %s", "Sports", topResult)
+	}
+	if count := decayed.totalCountInCategory("Politics"); count <= 0 || count > 1e-6 {
+		t.Errorf("Expected a nearly forgotten category; actual: %g documents", count)
+	}
+	if count := decayed.totalCountInCategory("Sports"); count < 0.999 || count > 1 {
+		t.Errorf("Expected a fresh document; actual: %g documents", count)
+	}
+}
+
+func TestRecencyRebase(t *testing.T) {
+	r := newRecency(time.Hour)
+	start := time.Now().Add(-100 * time.Hour)
+	r.add([]feature{{word: "news", weight: 1}}, "Politics", 1, start)
+	r.add([]feature{{word: "news", weight: 1}}, "Politics", 1, start.Add(100*time.Hour))
+
+	if !r.epoch.Equal(start.Add(100 * time.Hour)) {
+		t.Errorf("Expected the epoch to move to the latest contribution")
+	}
+	if adjusted := 2 + r.docAdjustment("Politics"); adjusted < 0.99 || adjusted > 1.01 {
+		t.Errorf("Expected about one document's weight; actual: %f", adjusted)
+	}
+}
+
+func TestWithHalfLifeInvalid(t *testing.T) {
+	for _, halfLife := range []time.Duration{0, -time.Hour} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for a half-life of %v", halfLife)
+				}
+			}()
+			WithHalfLife(halfLife)
+		}()
+	}
+}
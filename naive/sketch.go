@@ -0,0 +1,97 @@
+package naive
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// countMinSketch approximates counts for an unbounded set of keys in a fixed
+// amount of memory. Each key is hashed into one counter per row and its count
+// is estimated as the minimum of those counters, so collisions can only ever
+// inflate an estimate, never reduce it. The number of distinct words is
+// estimated alongside by linear counting over a bitmap of 64 bits per column.
+type countMinSketch struct {
+	width int
+	table [][]float64
+	words []uint64
+}
+
+func newCountMinSketch(width, depth int) *countMinSketch {
+	table := make([][]float64, depth)
+	for i := range table {
+		table[i] = make([]float64, width)
+	}
+	return &countMinSketch{width: width, table: table, words: make([]uint64, width)}
+}
+
+// clone returns a deep copy of the sketch
+func (s *countMinSketch) clone() *countMinSketch {
+	table := make([][]float64, len(s.table))
+	for i, row := range s.table {
+		table[i] = append([]float64(nil), row...)
+	}
+	return &countMinSketch{width: s.width, table: table, words: append([]uint64(nil), s.words...)}
+}
+
+func (s *countMinSketch) add(word, category string, n float64) {
+	h1, h2 := sketchHash(word, category)
+	for i, row := range s.table {
+		row[s.index(h1, h2, i)] += n
+	}
+
+	bit := wordHash(word) % uint64(64*len(s.words))
+	s.words[bit/64] |= 1 << (bit % 64)
+}
+
+// vocabulary estimates the number of distinct words added to the sketch from
+// the fraction of bitmap bits still unset. A saturated bitmap can no longer
+// tell how many words there are, so the estimate is capped at the number
+// that would be expected to saturate it.
+func (s *countMinSketch) vocabulary() float64 {
+	m := float64(64 * len(s.words))
+	unset := 0
+	for _, w := range s.words {
+		unset += 64 - bits.OnesCount64(w)
+	}
+	if unset == 0 {
+		return m * math.Log(m)
+	}
+	return -m * math.Log(float64(unset)/m)
+}
+
+func (s *countMinSketch) estimate(word, category string) float64 {
+	h1, h2 := sketchHash(word, category)
+	min := math.Inf(1)
+	for i, row := range s.table {
+		if v := row[s.index(h1, h2, i)]; v < min {
+			min = v
+		}
+	}
+	if math.IsInf(min, 1) {
+		return 0
+	}
+	return min
+}
+
+func (s *countMinSketch) index(h1, h2 uint64, row int) int {
+	return int((h1 + uint64(row)*h2) % uint64(s.width))
+}
+
+// sketchHash derives two hashes of the (word, category) pair, with FNV-1a and
+// FNV-1, which are combined per row using double hashing. The second hash is
+// made odd so it never degenerates to a single column.
+func sketchHash(word, category string) (uint64, uint64) {
+	key := []byte(word + "\x00" + category)
+	h1, h2 := fnv.New64a(), fnv.New64()
+	h1.Write(key)
+	h2.Write(key)
+	return h1.Sum64(), h2.Sum64() | 1
+}
+
+// wordHash hashes a word for the distinct word bitmap
+func wordHash(word string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(word))
+	return h.Sum64()
+}
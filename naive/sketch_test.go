@@ -0,0 +1,91 @@
+package naive
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestWithCountMinSketch(t *testing.T) {
+	exact := New()
+	approximate := New(WithCountMinSketch(512, 4))
+
+	for i := 0; i < 50; i++ {
+		for _, c := range []*Classifier{exact, approximate} {
+			c.TrainString(fmt.Sprintf("kitty whiskers meow%d", i), "Cat")
+			c.TrainString(fmt.Sprintf("puppy bark woof%d", i), "Dog")
+			c.TrainString(fmt.Sprintf("guppy bubbles swim%d", i), "Fish")
+		}
+	}
+
+	if len(approximate.Feat2cat) != 0 {
+		t.Errorf("Expected no exact counts; actual: %d words", len(approximate.Feat2cat))
+	}
+
+	inputs := []string{"kitty", "whiskers meow", "puppy", "bark woof", "guppy", "swim bubbles", "kitty bark", "meow3", "woof7"}
+	agree := 0
+	for _, input := range inputs {
+		_, expected := exact.Probabilities(input)
+		_, actual := approximate.Probabilities(input)
+		if expected == actual {
+			agree++
+		}
+	}
+
+	if accuracy := float64(agree) / float64(len(inputs)); accuracy < 0.8 {
+		t.Errorf("Expected the sketch to agree with exact counts on at least 80%% of inputs; actual: %.0f%%", 100*accuracy)
+	}
+}
+
+func TestCountMinSketchOverestimates(t *testing.T) {
+	sketch := newCountMinSketch(8, 2)
+	for i := 0; i < 100; i++ {
+		sketch.add(fmt.Sprint(i), "Cat", 1)
+	}
+	for i := 0; i < 100; i++ {
+		if estimate := sketch.estimate(fmt.Sprint(i), "Cat"); estimate < 1 {
+			t.Errorf("Expected an estimate of at least 1; actual: %v", estimate)
+		}
+	}
+}
+
+func TestCountMinSketchUnseenWord(t *testing.T) {
+	c := New(WithCountMinSketch(512, 4))
+	c.TrainString("White kitty", "Cat")
+	c.TrainString("Black kitty", "Cat")
+	c.TrainString("Brown shepherd", "Dog")
+
+	probabilities, topResult := c.Probabilities("white zebra")
+	if topResult != "Cat" || len(probabilities) != 2 {
+		t.Errorf("Expected both categories with %s on top; actual: %v", "Cat", probabilities)
+	}
+	for category, p := range probabilities {
+		if math.IsNaN(p) || p <= 0 {
+			t.Errorf("Expected a positive probability for %s; actual: %v", category, p)
+		}
+	}
+}
+
+func TestCountMinSketchVocabulary(t *testing.T) {
+	sketch := newCountMinSketch(64, 2)
+	for i := 0; i < 500; i++ {
+		sketch.add(fmt.Sprint("word", i), "Cat", 1)
+		sketch.add(fmt.Sprint("word", i), "Dog", 1)
+	}
+	if estimate := sketch.vocabulary(); math.Abs(estimate-500) > 50 {
+		t.Errorf("Expected about %d words; actual: %v", 500, estimate)
+	}
+}
+
+func TestWithCountMinSketchInvalid(t *testing.T) {
+	for _, dimensions := range [][2]int{{0, 2}, {2, 0}, {-1, 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for %v", dimensions)
+				}
+			}()
+			WithCountMinSketch(dimensions[0], dimensions[1])
+		}()
+	}
+}
@@ -0,0 +1,171 @@
+package naive
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+)
+
+// Snapshot is an immutable, internally consistent copy of a model's counts
+// that can be serialized while the classifier keeps training
+type Snapshot struct {
+	model model
+}
+
+// Snapshot captures the model's counts without copying them. The counts are
+// marked as shared and copied on write instead: the first training call
+// afterwards copies the word index, and every word it changes is copied before
+// it is modified, so taking a snapshot costs the same for any model size. It
+// is not supported for count-min sketch models since their vocabulary cannot
+// be enumerated.
+func (c *Classifier) Snapshot() (*Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sketch != nil {
+		return nil, errors.New("count-min sketch models cannot be serialized")
+	}
+
+	c.shared = true
+	return &Snapshot{model: model{
+		FormatVersion: FormatVersion,
+		Signature:     tokenizerSignature(c.Tokenizer),
+		Feat2cat:      c.Feat2cat,
+		CatCount:      c.CatCount,
+		DocFreq:       c.docFreq,
+	}}, nil
+}
+
+// unshare gives the classifier its own word index and document counts if a
+// snapshot still references them, leaving the per-word counts to be copied by
+// ownWord; callers must hold the write lock
+func (c *Classifier) unshare() {
+	if !c.shared {
+		return
+	}
+	feat2cat := make(map[string]map[string]float64, len(c.Feat2cat))
+	for word, counts := range c.Feat2cat {
+		feat2cat[word] = counts
+	}
+	c.Feat2cat = feat2cat
+	c.CatCount = copyCounts(c.CatCount)
+	c.docFreq = copyCounts(c.docFreq)
+	c.owned = make(map[string]bool)
+	c.shared = false
+}
+
+// ownWord copies the counts of the word before they are modified if they may
+// still be referenced by a snapshot; callers must hold the write lock
+func (c *Classifier) ownWord(word string) {
+	c.unshare()
+	if c.owned == nil || c.owned[word] {
+		return
+	}
+	if counts, ok := c.Feat2cat[word]; ok {
+		c.Feat2cat[word] = copyCounts(counts)
+	}
+	c.owned[word] = true
+}
+
+// Clone returns a deep copy of the classifier that can be trained
+// independently of it. The copy shares the tokenizer, hook, logger and
+// tiebreaker and keeps every option. It is taken under the read lock, so a
+// classifier can be cloned while it is queried.
+func (c *Classifier) Clone() *Classifier {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clone := &Classifier{
+		Feat2cat:    copyFeat2cat(c.Feat2cat),
+		CatCount:    copyCounts(c.CatCount),
+		Tokenizer:   c.Tokenizer,
+		Alpha:       c.Alpha,
+		Concurrency: c.Concurrency,
+		Logger:      c.Logger,
+
+		floor:      c.floor,
+		mapped:     c.mapped,
+		display:    c.display,
+		adaptive:   c.adaptive,
+		ngrams:     c.ngrams,
+		retain:     c.retain,
+		strict:     c.strict,
+		unique:     c.unique,
+		tiebreaker: c.tiebreaker,
+		prior:      c.prior,
+		minDocs:    c.minDocs,
+		normalized: c.normalized,
+		decay:      c.decay,
+		hook:       c.hook,
+		earlyStop:  c.earlyStop,
+		tfidf:      c.tfidf,
+		complement: c.complement,
+		unknown:    c.unknown,
+		fallback:   c.fallback,
+		level:      c.level,
+		salt:       c.salt,
+		examples:   append([]Example(nil), c.examples...),
+		catTokens:  copyCounts(c.catTokens),
+		docFreq:    copyCounts(c.docFreq),
+	}
+	if c.priors != nil {
+		clone.priors = copyCounts(c.priors)
+	}
+	if c.sketch != nil {
+		clone.sketch = c.sketch.clone()
+	}
+	if c.recency != nil {
+		clone.recency = c.recency.clone()
+	}
+	if c.folded != nil {
+		clone.folded = make(map[string]map[string]bool)
+		for word := range clone.Feat2cat {
+			clone.foldWord(word)
+		}
+	}
+	c.histMu.Lock()
+	if c.histogram != nil {
+		clone.histogram = append([]int(nil), c.histogram...)
+	}
+	c.histMu.Unlock()
+	return clone
+}
+
+// copyFeat2cat returns a deep copy of the word counts
+func copyFeat2cat(feat2cat map[string]map[string]float64) map[string]map[string]float64 {
+	copied := make(map[string]map[string]float64, len(feat2cat))
+	for word, counts := range feat2cat {
+		copied[word] = copyCounts(counts)
+	}
+	return copied
+}
+
+// copyCounts returns a copy of the counts
+func copyCounts(counts map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(counts))
+	for key, count := range counts {
+		copied[key] = count
+	}
+	return copied
+}
+
+// WriteTo streams the snapshot to w with encoding/gob without holding any
+// lock on the classifier it was taken from. The output can be read back with
+// Load.
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := gob.NewEncoder(cw).Encode(s.model)
+	return cw.n, err
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,110 @@
+package naive
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	trained := New()
+	trained.TrainString("Kitty whiskers", "Cat")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			trained.TrainString("Kitty whiskers", "Cat")
+			trained.TrainString("Puppy", "Dog")
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		snapshot, err := trained.Snapshot()
+		if err != nil {
+			t.Fatalf("unable to snapshot: %v", err)
+		}
+		var buf bytes.Buffer
+		n, err := snapshot.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("unable to write: %v", err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("Expected %d bytes; actual: %d", buf.Len(), n)
+		}
+
+		loaded := New()
+		if err := loaded.decode(&buf); err != nil {
+			t.Fatalf("unable to load: %v", err)
+		}
+
+		// every trained document adds one count to each of its words, so a
+		// torn snapshot would disagree with its own document counts
+		cats, dogs := loaded.CatCount["Cat"], loaded.CatCount["Dog"]
+		if loaded.Feat2cat["kitty"]["Cat"] != cats || loaded.Feat2cat["whiskers"]["Cat"] != cats || loaded.Feat2cat["puppy"]["Dog"] != dogs {
+			t.Errorf("Expected a consistent snapshot; actual: %v, %v", loaded.CatCount, loaded.Feat2cat)
+		}
+		if cats < dogs+1 || cats > dogs+2 {
+			t.Errorf("Expected a snapshot between two training calls; actual: %v", loaded.CatCount)
+		}
+	}
+	wg.Wait()
+
+	if _, err := New(WithCountMinSketch(16, 2)).Snapshot(); err == nil {
+		t.Errorf("Expected an error for a count-min sketch model")
+	}
+}
+
+func TestClone(t *testing.T) {
+	source := New(WithCaseFallback())
+	source.TrainString("White kitty", "Cat")
+	source.TrainString("German shepherd", "Dog")
+	feat2cat := fmt.Sprint(source.Feat2cat)
+	catCount := fmt.Sprint(source.CatCount)
+
+	clone := source.Clone()
+	if !reflect.DeepEqual(source.Feat2cat, clone.Feat2cat) || !reflect.DeepEqual(source.CatCount, clone.CatCount) {
+		t.Errorf("Expected %v; actual: %v", source.Feat2cat, clone.Feat2cat)
+	}
+
+	clone.TrainString("White kitty", "Dog")
+	clone.TrainString("Tabby", "Cat")
+	if actual := fmt.Sprint(source.Feat2cat); actual != feat2cat {
+		t.Errorf("Expected %v; actual: %v", feat2cat, actual)
+	}
+	if actual := fmt.Sprint(source.CatCount); actual != catCount {
+		t.Errorf("Expected %v; actual: %v", catCount, actual)
+	}
+	if clone.Feat2cat["kitty"]["Dog"] != 1 || clone.CatCount["Cat"] != 2 {
+		t.Errorf("Expected the clone to be trained; actual: %v, %v", clone.Feat2cat, clone.CatCount)
+	}
+	if _, topResult := clone.Probabilities("TABBY"); topResult != "Cat" {
+		t.Errorf("Expected the clone to keep case fallback; actual: %s", topResult)
+	}
+}
+
+func TestSnapshotCopyOnWrite(t *testing.T) {
+	c := New()
+	c.TrainString("Kitty whiskers", "Cat")
+
+	snapshot, err := c.Snapshot()
+	if err != nil {
+		t.Fatalf("unable to snapshot: %v", err)
+	}
+	c.TrainString("Kitty", "Cat")
+	c.TrainString("Puppy", "Dog")
+	if err := c.RemoveCategory("Dog"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]map[string]float64{"kitty": {"Cat": 1}, "whiskers": {"Cat": 1}}
+	if !reflect.DeepEqual(snapshot.model.Feat2cat, expected) || !reflect.DeepEqual(snapshot.model.CatCount, map[string]float64{"Cat": 1}) {
+		t.Errorf("Expected the snapshot to be unaffected by training; actual: %v, %v", snapshot.model.Feat2cat, snapshot.model.CatCount)
+	}
+	if c.Feat2cat["kitty"]["Cat"] != 2 || c.CatCount["Cat"] != 2 {
+		t.Errorf("Expected training to continue after the snapshot; actual: %v, %v", c.Feat2cat, c.CatCount)
+	}
+}
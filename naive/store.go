@@ -0,0 +1,139 @@
+package naive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidKey is returned by FileStore for keys that do not name a file
+// below its root directory
+var ErrInvalidKey = errors.New("invalid store key")
+
+// Store persists serialized models under a key, e.g. in object storage, a
+// key-value store or a database
+type Store interface {
+	// Save stores data under key, replacing any previous value
+	Save(key string, data []byte) error
+	// Load returns the data stored under key
+	Load(key string) ([]byte, error)
+}
+
+// SaveTo serializes the model and saves it in the store under key
+func (c *Classifier) SaveTo(store Store, key string) error {
+	var buf bytes.Buffer
+	if err := c.encode(&buf); err != nil {
+		return err
+	}
+	return store.Save(key, buf.Bytes())
+}
+
+// LoadFrom replaces the model with the one saved in the store under key. The
+// configured tokenizer is kept and checked against the one the model was
+// saved with.
+func (c *Classifier) LoadFrom(store Store, key string) error {
+	data, err := store.Load(key)
+	if err != nil {
+		return err
+	}
+	return c.decode(bytes.NewReader(data))
+}
+
+// MemoryStore is a Store that keeps models in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStore initializes an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string][]byte)}
+}
+
+// Save stores a copy of data under key
+func (s *MemoryStore) Save(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Load returns a copy of the data stored under key, failing with an error
+// wrapping os.ErrNotExist when there is none
+func (s *MemoryStore) Load(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.data[key]
+	if !ok {
+		return nil, fmt.Errorf("key not found: %s: %w", key, os.ErrNotExist)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// FileStore is a Store that keeps every model in its own file below a root
+// directory, using the key as the file's path relative to the root. Keys that
+// resolve outside the root, such as "../model", are rejected.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore initializes a FileStore rooted at the given directory
+func NewFileStore(root string) *FileStore {
+	return &FileStore{root: root}
+}
+
+// Save writes data to the key's file, creating missing directories. The data
+// is written to a temporary file first and renamed into place, so a failed
+// save never leaves a truncated model behind.
+func (s *FileStore) Save(key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load reads the key's file
+func (s *FileStore) Load(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// path returns the cleaned path of the key's file, failing with
+// ErrInvalidKey unless it lies below the root
+func (s *FileStore) path(key string) (string, error) {
+	root := filepath.Clean(s.root)
+	path := filepath.Join(root, key)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return path, nil
+}
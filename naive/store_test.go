@@ -0,0 +1,67 @@
+package naive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   NewFileStore(t.TempDir()),
+	}
+
+	for name, store := range stores {
+		trained := New(WithProbabilityFloor(0.01))
+		trained.TrainString("Black kitty", "Cat")
+		trained.TrainString("White kitty", "Cat")
+		trained.TrainString("German shepherd", "Dog")
+
+		if err := trained.SaveTo(store, "models/pets"); err != nil {
+			t.Fatalf("%s: unable to save: %v", name, err)
+		}
+
+		loaded := New(WithProbabilityFloor(0.01))
+		if err := loaded.LoadFrom(store, "models/pets"); err != nil {
+			t.Fatalf("%s: unable to load: %v", name, err)
+		}
+
+		for _, input := range []string{"Kitty", "Shepherd"} {
+			expected, expectedTop := trained.Probabilities(input)
+			actual, actualTop := loaded.Probabilities(input)
+			if expectedTop != actualTop {
+				t.Errorf("%s: expected %s; actual: %s", name, expectedTop, actualTop)
+			}
+			for category, p := range expected {
+				assertFloat(t, name+" "+category, p, actual[category])
+			}
+		}
+
+		if err := loaded.LoadFrom(store, "missing"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s: expected %v; actual: %v", name, os.ErrNotExist, err)
+		}
+	}
+}
+
+func TestFileStoreInvalidKey(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "models")
+	store := NewFileStore(root)
+
+	for _, key := range []string{"../escaped", "a/../../escaped", "", "."} {
+		if err := store.Save(key, []byte("model")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%q: expected %v; actual: %v", key, ErrInvalidKey, err)
+		}
+		if _, err := store.Load(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%q: expected %v; actual: %v", key, ErrInvalidKey, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escaped")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file outside the root; actual: %v", err)
+	}
+
+	if err := store.Save("a/../pets", []byte("model")); err != nil {
+		t.Errorf("Expected a key within the root to be accepted; actual: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package naive
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrAmbiguous is returned when a classification has no single best category
+var ErrAmbiguous = errors.New("ambiguous classification")
+
+// WithTiebreaker configures a secondary classifier, e.g. one trained on
+// different features, that ClassifyStrict consults to decide between
+// categories tied for the top score
+func (c *Classifier) WithTiebreaker(secondary *Classifier) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tiebreaker = secondary
+}
+
+// ClassifyStrict returns the top category for the provided string, or
+// ErrAmbiguous when nothing scored or several categories share the top score.
+// Ties are first handed to the tiebreaker, if any, which scores only the tied
+// categories.
+func (c *Classifier) ClassifyStrict(s string) (string, error) {
+	c.mu.RLock()
+	scores, _ := c.logScoresFor(c.features(s), c.getAllCategories())
+	tiebreaker := c.tiebreaker
+	c.mu.RUnlock()
+
+	tied := topCategories(scores)
+	if len(tied) == 1 {
+		return tied[0], nil
+	}
+	if len(tied) == 0 || tiebreaker == nil {
+		return "", ErrAmbiguous
+	}
+
+	probabilities, _ := tiebreaker.ProbabilitiesFor(s, tied)
+	if tied = topCategories(probabilities); len(tied) == 1 {
+		return tied[0], nil
+	}
+	return "", ErrAmbiguous
+}
+
+// topCategories returns every category sharing the highest score, sorted
+func topCategories(probabilities map[string]float64) []string {
+	var top []string
+	best := 0.0
+	for category, p := range probabilities {
+		switch {
+		case top == nil || p > best:
+			top, best = []string{category}, p
+		case p == best:
+			top = append(top, category)
+		}
+	}
+	sort.Strings(top)
+	return top
+}
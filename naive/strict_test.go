@@ -0,0 +1,30 @@
+package naive
+
+import "testing"
+
+func TestClassifyStrict(t *testing.T) {
+	primary := New()
+	primary.TrainString("Fluffy pet", "Cat")
+	primary.TrainString("Fluffy pet", "Dog")
+	primary.TrainString("Scaly pet", "Fish")
+
+	if actual, err := primary.ClassifyStrict("Scaly"); err != nil || actual != "Fish" {
+		t.Errorf("Expected %s; actual: %s, %v", "Fish", actual, err)
+	}
+	if _, err := primary.ClassifyStrict("Fluffy"); err != ErrAmbiguous {
+		t.Errorf("Expected %v; actual: %v", ErrAmbiguous, err)
+	}
+
+	secondary := New()
+	secondary.TrainString("Fluffy whiskers", "Cat")
+	secondary.TrainString("Fluffy fur", "Cat")
+	secondary.TrainString("Fluffy tail", "Dog")
+	secondary.TrainString("Fluffy fins", "Fish")
+	secondary.TrainString("Fluffy fins", "Fish")
+	secondary.TrainString("Fluffy fins", "Fish")
+	primary.WithTiebreaker(secondary)
+
+	if actual, err := primary.ClassifyStrict("Fluffy"); err != nil || actual != "Cat" {
+		t.Errorf("Expected the tiebreaker to pick %s among the tied categories; actual: %s, %v", "Cat", actual, err)
+	}
+}
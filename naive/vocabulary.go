@@ -0,0 +1,180 @@
+package naive
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Vocabulary returns every word known to the model, sorted
+func (c *Classifier) Vocabulary() []string {
+	c.mu.RLock()
+	words := make([]string, 0, len(c.Feat2cat))
+	for word := range c.Feat2cat {
+		words = append(words, word)
+	}
+	c.mu.RUnlock()
+
+	sort.Strings(words)
+	return words
+}
+
+// VocabularySize returns the number of distinct words known to the model,
+// which is an estimate for count-min sketch models
+func (c *Classifier) VocabularySize() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.vocabularySize()
+}
+
+// WordCount returns the number of times the word was trained across all
+// categories, scaled by training weights, or 0 for an unknown word. Counts
+// are as trained, before any decay configured with WithHalfLife.
+func (c *Classifier) WordCount(word string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	word = c.hashWord(word)
+	if c.sketch != nil {
+		sum := 0.0
+		for category := range c.CatCount {
+			sum += c.sketch.estimate(word, category)
+		}
+		return sum
+	}
+	if c.mapped != nil {
+		return c.mapped.total(word)
+	}
+	return sumCounts(c.Feat2cat[word])
+}
+
+// WordCountInCategory returns the number of times the word was trained in the
+// category, scaled by training weights, or 0 for an unknown word or category.
+// Counts are as trained, before any decay configured with WithHalfLife.
+func (c *Classifier) WordCountInCategory(word, category string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	word = c.hashWord(word)
+	if c.sketch != nil {
+		return c.sketch.estimate(word, category)
+	}
+	if c.mapped != nil {
+		return c.mapped.count(word, category)
+	}
+	return c.Feat2cat[word][category]
+}
+
+// ExportVocabulary writes every word known to the model, sorted, one per line
+func (c *Classifier) ExportVocabulary(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, word := range c.Vocabulary() {
+		bw.WriteString(word)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// RestrictToVocabulary prunes the model down to the words listed, one per
+// line, in the supplied vocabulary and returns the number of words dropped.
+// Document counts are left untouched.
+func (c *Classifier) RestrictToVocabulary(r io.Reader) (int, error) {
+	vocabulary := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			vocabulary[word] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := 0
+	for word := range c.Feat2cat {
+		if !vocabulary[word] {
+			c.removeWord(word)
+			dropped++
+		}
+	}
+	return dropped, nil
+}
+
+// Prune drops every word trained fewer than minTotalCount times across all
+// categories and returns the number of words dropped. Document counts are
+// left untouched.
+func (c *Classifier) Prune(minTotalCount float64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := 0
+	for word, counts := range c.Feat2cat {
+		if sumCounts(counts) < minTotalCount {
+			c.removeWord(word)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// PruneToTopK keeps only the k words trained most often across all
+// categories, breaking ties by word, and returns the number of words
+// dropped. Document counts are left untouched. A negative k is treated as
+// zero.
+func (c *Classifier) PruneToTopK(k int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if k < 0 {
+		k = 0
+	}
+
+	scores := make([]CategoryScore, 0, len(c.Feat2cat))
+	for word, counts := range c.Feat2cat {
+		scores = append(scores, CategoryScore{Label: word, Score: sumCounts(counts)})
+	}
+	sortScores(scores)
+
+	dropped := 0
+	for i := k; i < len(scores); i++ {
+		c.removeWord(scores[i].Label)
+		dropped++
+	}
+	return dropped
+}
+
+// sumCounts sums the counts
+func sumCounts(counts map[string]float64) float64 {
+	sum := 0.0
+	for _, count := range counts {
+		sum += count
+	}
+	return sum
+}
+
+// removeWord deletes the word from every category; callers must hold the
+// write lock
+func (c *Classifier) removeWord(word string) {
+	for category, count := range c.Feat2cat[word] {
+		c.catTokens[category] -= count
+	}
+	c.unshare()
+	delete(c.Feat2cat, word)
+	delete(c.docFreq, word)
+	if c.recency != nil {
+		c.recency.remove(word)
+	}
+
+	if c.folded != nil {
+		key := strings.ToLower(word)
+		delete(c.folded[key], word)
+		if len(c.folded[key]) == 0 {
+			delete(c.folded, key)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package naive
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportVocabulary(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	var buf bytes.Buffer
+	if err := classifier.ExportVocabulary(&buf); err != nil {
+		t.Fatalf("unable to export vocabulary: %v", err)
+	}
+
+	expected := "german\nkitty\nshepherd\nwhite\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q; actual: %q", expected, buf.String())
+	}
+
+	other := New()
+	other.TrainString("White kitty", "Cat")
+	other.TrainString("German shepherd", "Dog")
+	dropped, err := other.RestrictToVocabulary(&buf)
+	if err != nil {
+		t.Fatalf("unable to restrict vocabulary: %v", err)
+	}
+	if dropped != 0 || !reflect.DeepEqual(classifier.Feat2cat, other.Feat2cat) {
+		t.Errorf("Expected a round trip to keep the model intact; dropped %d", dropped)
+	}
+}
+
+func TestRestrictToVocabulary(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("Black kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	dropped, err := classifier.RestrictToVocabulary(bytes.NewBufferString("kitty\nshepherd\nunknown\n"))
+	if err != nil {
+		t.Fatalf("unable to restrict vocabulary: %v", err)
+	}
+	if dropped != 3 {
+		t.Errorf("Expected %d dropped words; actual: %d", 3, dropped)
+	}
+	if len(classifier.Feat2cat) != 2 {
+		t.Errorf("Expected %d remaining words; actual: %d", 2, len(classifier.Feat2cat))
+	}
+	if classifier.catTokens["Cat"] != 2 || classifier.CatCount["Cat"] != 2 {
+		t.Errorf("Expected 2 tokens and documents for Cat; actual: %v tokens, %v documents", classifier.catTokens["Cat"], classifier.CatCount["Cat"])
+	}
+}
+
+func TestWithFeatureHashingSalt(t *testing.T) {
+	train := func(c *Classifier) {
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("German shepherd", "Dog")
+		c.TrainString("White pointer", "Dog")
+	}
+
+	plain := New(WithProbabilityFloor(0.01))
+	train(plain)
+	hashed := New(WithProbabilityFloor(0.01), WithFeatureHashingSalt("pepper"))
+	train(hashed)
+
+	for _, input := range []string{"Kitty", "German pointer", "Black kitty pointer"} {
+		expected, expectedTop := plain.Probabilities(input)
+		actual, actualTop := hashed.Probabilities(input)
+		if expectedTop != actualTop {
+			t.Errorf("%s: expected %s; actual: %s", input, expectedTop, actualTop)
+		}
+		for category, p := range expected {
+			assertFloat(t, input+" "+category, p, actual[category])
+		}
+	}
+
+	vocabulary := hashed.Vocabulary()
+	if len(vocabulary) != len(plain.Vocabulary()) {
+		t.Errorf("Expected %d words; actual: %d", len(plain.Vocabulary()), len(vocabulary))
+	}
+	for _, word := range vocabulary {
+		for _, plaintext := range plain.Vocabulary() {
+			if strings.Contains(word, plaintext) {
+				t.Errorf("Expected only hashes; actual: %s", word)
+			}
+		}
+	}
+
+	other := New(WithFeatureHashingSalt("salt"))
+	train(other)
+	if reflect.DeepEqual(vocabulary, other.Vocabulary()) {
+		t.Errorf("Expected the salt to change the hashes")
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	for _, c := range []*Classifier{New(), New(WithFeatureHashingSalt("pepper"))} {
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White shepherd", "Dog")
+
+		if size := c.VocabularySize(); size != 4 {
+			t.Errorf("Expected %d; actual: %d", 4, size)
+		}
+		if count := c.WordCount("white"); count != 2 {
+			t.Errorf("Expected %v; actual: %v", 2, count)
+		}
+		if count := c.WordCountInCategory("kitty", "Cat"); count != 2 {
+			t.Errorf("Expected %v; actual: %v", 2, count)
+		}
+		if count := c.WordCount("parrot"); count != 0 {
+			t.Errorf("Expected %v; actual: %v", 0, count)
+		}
+		if count := c.WordCountInCategory("kitty", "Bird"); count != 0 {
+			t.Errorf("Expected %v; actual: %v", 0, count)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	train := func() *Classifier {
+		c := New()
+		c.TrainString("White kitty", "Cat")
+		c.TrainString("Black kitty", "Cat")
+		c.TrainString("White shepherd", "Dog")
+		c.TrainString("Shepherd puppy", "Dog")
+		return c
+	}
+
+	pruned := train()
+	if dropped := pruned.Prune(2); dropped != 2 {
+		t.Errorf("Expected %d; actual: %d", 2, dropped)
+	}
+	if size := pruned.VocabularySize(); size != 3 {
+		t.Errorf("Expected %d; actual: %d", 3, size)
+	}
+	if pruned.TotalDocumentCount() != 4 {
+		t.Errorf("Expected %v; actual: %v", 4, pruned.TotalDocumentCount())
+	}
+
+	// both categories have as many documents, so unseen words leave the
+	// normalized probabilities unchanged
+	expected, _ := pruned.ProbabilitiesNormalized("kitty")
+	actual, _ := pruned.ProbabilitiesNormalized("black kitty puppy")
+	for category, p := range expected {
+		assertFloat(t, category, p, actual[category])
+	}
+
+	top := train()
+	if dropped := top.PruneToTopK(2); dropped != 3 {
+		t.Errorf("Expected %d; actual: %d", 3, dropped)
+	}
+	if vocabulary := top.Vocabulary(); !reflect.DeepEqual(vocabulary, []string{"kitty", "shepherd"}) {
+		t.Errorf("Expected %v; actual: %v", []string{"kitty", "shepherd"}, vocabulary)
+	}
+	if dropped := top.PruneToTopK(-1); dropped != 2 {
+		t.Errorf("Expected %d; actual: %d", 2, dropped)
+	}
+}
@@ -0,0 +1,27 @@
+package naive
+
+import "sort"
+
+// warmCache holds values the classification path would otherwise derive from
+// the model on every call
+type warmCache struct {
+	total      float64
+	categories []string
+}
+
+// Warmup precomputes and caches the document total and the sorted category
+// list used by every classification, so the first requests after startup do
+// not pay for deriving them. Any subsequent training invalidates the cache
+// until Warmup is called again.
+func (c *Classifier) Warmup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.warm = nil
+	categories := c.getAllCategories()
+	sort.Strings(categories)
+	c.warm = &warmCache{
+		total:      c.countOfAllResults(),
+		categories: categories,
+	}
+}
@@ -0,0 +1,41 @@
+package naive
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	classifier := New()
+	classifier.TrainString("White kitty", "Cat")
+	classifier.TrainString("German shepherd", "Dog")
+
+	before, _ := classifier.Probabilities("White kitty")
+	classifier.Warmup()
+
+	if classifier.warm == nil {
+		t.Fatalf("Expected a warm cache")
+	}
+	cached := classifier.warm
+	classifier.warm = nil
+	if cached.total != classifier.countOfAllResults() {
+		t.Errorf("Expected a cached total of %v; actual: %v", classifier.countOfAllResults(), cached.total)
+	}
+	if fmt.Sprint(cached.categories) != "[Cat Dog]" {
+		t.Errorf("Expected sorted categories; actual: %v", cached.categories)
+	}
+	classifier.warm = cached
+
+	after, _ := classifier.Probabilities("White kitty")
+	if fmt.Sprint(before) != fmt.Sprint(after) {
+		t.Errorf("Expected %v; actual: %v", before, after)
+	}
+
+	classifier.TrainString("Guppy", "Fish")
+	if classifier.warm != nil {
+		t.Errorf("Expected training to invalidate the cache")
+	}
+	if total := classifier.countOfAllResults(); total != 3 {
+		t.Errorf("Expected a total of %v; actual: %v", 3, total)
+	}
+}
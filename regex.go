@@ -0,0 +1,46 @@
+package classifier
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// RegexTokenizer emits every match of a regular expression as a token,
+// leaving the text of each match unchanged
+type RegexTokenizer struct {
+	pattern *regexp.Regexp
+}
+
+// NewRegexTokenizer initializes a tokenizer that emits every non-empty match
+// of pattern, e.g. `[A-Za-z]+-\d+|\w+` keeps SKUs like "ABC-123" in one
+// piece. It returns an error if the pattern does not compile.
+func NewRegexTokenizer(pattern string) (Tokenizer, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token pattern: %w", err)
+	}
+	return &RegexTokenizer{pattern: re}, nil
+}
+
+// Tokenize emits the matches found in the document
+func (t *RegexTokenizer) Tokenize(r io.Reader) chan string {
+	tokens := make(chan string, defaultBufferSize)
+
+	go func() {
+		text, _ := io.ReadAll(r)
+		for _, match := range t.pattern.FindAll(text, -1) {
+			if len(match) > 0 {
+				tokens <- string(match)
+			}
+		}
+		close(tokens)
+	}()
+
+	return tokens
+}
+
+// Signature describes the pattern used by the tokenizer
+func (t *RegexTokenizer) Signature() string {
+	return fmt.Sprintf("regex(%s)", t.pattern)
+}
@@ -0,0 +1,37 @@
+package classifier
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexTokenizer(t *testing.T) {
+	tokenizer, err := NewRegexTokenizer(`[A-Z]+-\d+|\w+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual []string
+	for token := range tokenizer.Tokenize(toReader("Order ABC-123 shipped")) {
+		actual = append(actual, token)
+	}
+	if expected := []string{"Order", "ABC-123", "shipped"}; strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+
+	words, _ := NewRegexTokenizer(`\w+`)
+	actual = nil
+	for token := range words.Tokenize(toReader("Order ABC-123 shipped")) {
+		actual = append(actual, token)
+	}
+	if expected := []string{"Order", "ABC", "123", "shipped"}; strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+	if tokenizer.(Signer).Signature() == words.(Signer).Signature() {
+		t.Errorf("Expected different patterns to change the signature")
+	}
+
+	if _, err := NewRegexTokenizer(`[`); err == nil {
+		t.Errorf("Expected an invalid pattern to return an error")
+	}
+}
@@ -18,12 +18,22 @@ var (
 	numStopwords = len(stopwords)
 )
 
+func init() {
+	// the list must be sorted for the binary search in IsStopWord
+	sort.Strings(stopwords)
+}
+
+// EnglishStopWords returns a copy of the built-in list of english stop words
+func EnglishStopWords() []string {
+	return append([]string(nil), stopwords...)
+}
+
 // IsStopWord performs a binary search against a list of known english stop words
 // returns true if v is a stop word; false otherwise
 func IsStopWord(v string) bool {
 	v = strings.ToLower(v)
 	index := sort.SearchStrings(stopwords, v)
-	return index >= numStopwords || stopwords[index] == v
+	return index < numStopwords && stopwords[index] == v
 }
 
 // IsNotStopWord is the inverse function of IsStopWord
@@ -12,7 +12,7 @@ func TestStopWords(t *testing.T) {
 		}
 	})
 	t.Run("Other", func(t *testing.T) {
-		sample := []string{"hello", "world"}
+		sample := []string{"hello", "world", "zebra", "!"}
 		for _, v := range sample {
 			if IsStopWord(v) {
 				t.Errorf("%s was incorrectly identified as a stop word", v)
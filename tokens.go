@@ -2,8 +2,19 @@ package classifier
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"fmt"
 	"io"
+	"reflect"
+	"runtime"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kljensen/snowball"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Tokenizer provides a common interface to tokenize documents
@@ -12,52 +23,206 @@ type Tokenizer interface {
 	Tokenize(io.Reader) chan string
 }
 
+// Stats reports how many tokens were read from a document and how many of
+// them were dropped by filters
+type Stats struct {
+	Read    int
+	Dropped int
+}
+
+// StatsTokenizer is implemented by tokenizers that can report statistics
+// about a tokenization
+type StatsTokenizer interface {
+	Tokenizer
+	// TokenizeStats behaves like Tokenize; the returned Stats are complete
+	// once the channel of tokens has been drained
+	TokenizeStats(io.Reader) (chan string, *Stats)
+}
+
+// Signer is implemented by tokenizers that can describe their configuration,
+// allowing models to detect when they are used with a different tokenizer
+// than the one they were trained with
+type Signer interface {
+	// Signature returns a description that changes whenever the tokens
+	// produced for a document could change
+	Signature() string
+}
+
 // StdOption provides configuration settings for a StdTokenizer
 type StdOption func(*StdTokenizer)
 
 // StdTokenizer provides a common document tokenizer that splits a
-// document by word boundaries
+// document by word boundaries. Every token is lowercased, unless disabled,
+// stripped of punctuation and stemmed, if configured, before the stop words
+// and other filters are applied, followed by any custom transforms. N-grams
+// are formed last.
 type StdTokenizer struct {
+	normalize  bool
+	lowercase  bool
+	strip      bool
+	stemmer    Mapper
+	language   string
 	transforms []Mapper
 	filters    []Predicate
+	stopwords  map[string]bool
+	stopwordID string
+	minLength  int
+	ngramMin   int
+	ngramMax   int
 	bufferSize int
 }
 
-// NewTokenizer initializes a new standard Tokenizer instance
+// englishStopWords identifies the built-in stop-word list in signatures; it
+// matches the name of the predicate that used to apply it
+const englishStopWords = "github.com/carautenbach/classifier.IsNotStopWord"
+
+// NewTokenizer initializes a new standard Tokenizer instance, which drops the
+// built-in english stop words
 func NewTokenizer(opts ...StdOption) *StdTokenizer {
 	tokenizer := &StdTokenizer{
 		bufferSize: 100,
-		transforms: []Mapper{
-			strings.ToLower,
-		},
-		filters: []Predicate{
-			IsNotStopWord,
-		},
+		lowercase:  true,
+		stopwords:  stopwordSet(stopwords),
+		stopwordID: englishStopWords,
+		minLength:  1,
+		ngramMin:   1,
+		ngramMax:   1,
 	}
 	for _, opt := range opts {
 		opt(tokenizer)
 	}
+	if tokenizer.stemmer != nil {
+		// stop words are matched after stemming, so their stems must match too
+		for word := range tokenizer.stopwords {
+			tokenizer.stopwords[tokenizer.stemmer(word)] = true
+		}
+	}
 	return tokenizer
 }
 
 // Tokenize words and return streaming results
 func (t *StdTokenizer) Tokenize(r io.Reader) chan string {
+	tokens, _ := t.TokenizeStats(r)
+	return tokens
+}
+
+// TokenizeStats tokenizes words and reports how many were read and dropped
+func (t *StdTokenizer) TokenizeStats(r io.Reader) (chan string, *Stats) {
+	stats := &Stats{}
+	if t.normalize {
+		r = norm.NFC.Reader(r)
+	}
 	tokenizer := bufio.NewScanner(r)
 	tokenizer.Split(bufio.ScanWords)
 	tokens := make(chan string, t.bufferSize)
 
 	go func() {
 		for tokenizer.Scan() {
+			stats.Read++
 			tokens <- tokenizer.Text()
 		}
 		close(tokens)
 	}()
 
-	return t.pipeline(tokens)
+	return t.pipeline(tokens, stats), stats
+}
+
+// Signature describes the transforms and filters applied by the tokenizer
+func (t *StdTokenizer) Signature() string {
+	var transforms []string
+	if t.lowercase {
+		transforms = append(transforms, funcName(foldCase))
+	}
+	for _, m := range t.transforms {
+		transforms = append(transforms, funcName(m))
+	}
+	var filters []string
+	if t.stopwords != nil {
+		filters = append(filters, t.stopwordID)
+	}
+	for _, f := range t.filters {
+		filters = append(filters, funcName(f))
+	}
+	var options string
+	if t.normalize {
+		options += ";normalization=nfc"
+	}
+	if t.strip {
+		options += ";punctuation=strip"
+	}
+	if t.stemmer != nil {
+		options += ";stemmer=" + t.language
+	}
+	if t.minLength > 1 {
+		options += fmt.Sprintf(";minlength=%d", t.minLength)
+	}
+	if t.ngramMin != 1 || t.ngramMax != 1 {
+		options += fmt.Sprintf(";ngrams=%d-%d", t.ngramMin, t.ngramMax)
+	}
+	return fmt.Sprintf("std(transforms=%s;filters=%s%s)", strings.Join(transforms, ","), strings.Join(filters, ","), options)
+}
+
+func funcName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+func (t *StdTokenizer) pipeline(in chan string, stats *Stats) chan string {
+	normalize := func(text string) string {
+		if t.lowercase {
+			text = foldCase(text)
+		}
+		if t.strip {
+			text = strings.TrimFunc(text, unicode.IsPunct)
+		}
+		if t.stemmer != nil {
+			text = t.stemmer(text)
+		}
+		return text
+	}
+	keep := func(text string) bool {
+		if text == "" || t.stopwords[foldCase(text)] || utf8.RuneCountInString(text) < t.minLength {
+			stats.Dropped++
+			return false
+		}
+		for _, f := range t.filters {
+			if !f(text) {
+				stats.Dropped++
+				return false
+			}
+		}
+		return true
+	}
+	tokens := Map(Filter(Map(in, normalize), keep), t.transforms...)
+	if t.ngramMin == 1 && t.ngramMax == 1 {
+		return tokens
+	}
+	return t.ngrams(tokens)
 }
 
-func (t *StdTokenizer) pipeline(in chan string) chan string {
-	return Map(Filter(in, t.filters...), t.transforms...)
+// ngramSeparator joins the words of an n-gram
+const ngramSeparator = "_"
+
+// ngrams emits, for every token, the n-grams of the configured range that end
+// with it, e.g. "german", "shepherd" and "german_shepherd" for a range of 1 to
+// 2. N-grams never span tokens dropped by the filters.
+func (t *StdTokenizer) ngrams(in chan string) chan string {
+	stream := make(chan string, t.bufferSize)
+
+	go func() {
+		window := make([]string, 0, t.ngramMax)
+		for token := range in {
+			if len(window) == t.ngramMax {
+				window = append(window[:0], window[1:]...)
+			}
+			window = append(window, token)
+			for n := t.ngramMin; n <= t.ngramMax && n <= len(window); n++ {
+				stream <- strings.Join(window[len(window)-n:], ngramSeparator)
+			}
+		}
+		close(stream)
+	}()
+
+	return stream
 }
 
 // BufferSize adjusts the size of the buffered channel
@@ -67,16 +232,156 @@ func BufferSize(size int) StdOption {
 	}
 }
 
-// Transforms overrides the list of mappers
+// Transforms overrides the list of mappers, which are applied after the
+// filters. This includes the lowercasing that is otherwise applied first.
 func Transforms(m ...Mapper) StdOption {
 	return func(t *StdTokenizer) {
 		t.transforms = m
+		t.lowercase = false
+	}
+}
+
+// WithUnicodeNormalization controls whether documents are normalized to
+// Unicode NFC before they are split, so that an accented character matches
+// whether it was written precomposed or with a combining mark. It is off by
+// default.
+func WithUnicodeNormalization(normalize bool) StdOption {
+	return func(t *StdTokenizer) {
+		t.normalize = normalize
+	}
+}
+
+// WithLowercase controls whether tokens are lowercased before anything else
+// is applied to them, which is on by default. Lowercasing uses Unicode case
+// folding, so "ÉCOLE" becomes "école" and "Straße" matches "STRASSE".
+func WithLowercase(lowercase bool) StdOption {
+	return func(t *StdTokenizer) {
+		t.lowercase = lowercase
+	}
+}
+
+// WithStripPunctuation controls whether leading and trailing punctuation is
+// removed from every token, so that "kitty." and "(kitty" both become
+// "kitty". Punctuation inside a token is kept, so "e-mail" and "don't" remain
+// single tokens. Tokens consisting only of punctuation are dropped. It is
+// off by default.
+func WithStripPunctuation(strip bool) StdOption {
+	return func(t *StdTokenizer) {
+		t.strip = strip
+	}
+}
+
+// WithMinTokenLength drops tokens of fewer than n characters, measured after
+// lowercasing, stripping and stemming. The default of 1 keeps every token.
+// It panics if n is less than 1.
+func WithMinTokenLength(n int) StdOption {
+	if n < 1 {
+		panic(fmt.Sprintf("invalid minimum token length: %d", n))
+	}
+
+	return func(t *StdTokenizer) {
+		t.minLength = n
+	}
+}
+
+// stemmerLanguages maps the names and ISO 639-1 codes accepted by WithStemmer
+// to the languages of the snowball stemmer
+var stemmerLanguages = map[string]string{
+	"english":   "english",
+	"en":        "english",
+	"spanish":   "spanish",
+	"es":        "spanish",
+	"french":    "french",
+	"fr":        "french",
+	"russian":   "russian",
+	"ru":        "russian",
+	"swedish":   "swedish",
+	"sv":        "swedish",
+	"norwegian": "norwegian",
+	"no":        "norwegian",
+	"nb":        "norwegian",
+}
+
+// WithStemmer reduces every token to its stem, so that e.g. "kitten" and
+// "kittens" become the same feature. Stemming runs after lowercasing and
+// before the stop words are filtered. The snowball stemmers for English,
+// Spanish, French, Russian, Swedish and Norwegian are supported, by name or
+// by language code; WithStemmer panics for any other language.
+func WithStemmer(language string) StdOption {
+	lang, ok := stemmerLanguages[strings.ToLower(language)]
+	if !ok {
+		panic(fmt.Sprintf("unsupported stemmer language: %s", language))
+	}
+
+	stem := func(word string) string {
+		stemmed, err := snowball.Stem(word, lang, true)
+		if err != nil {
+			return word
+		}
+		return stemmed
+	}
+	return func(t *StdTokenizer) {
+		t.stemmer = stem
+		t.language = lang
 	}
 }
 
-// Filters overrides the list of predicates
+// WithNGramRange emits every sequence of min to max consecutive tokens as a
+// feature, joining the words with an underscore, e.g. "german_shepherd" for
+// a range of 1 to 2. N-grams are formed from the tokens left after
+// lowercasing, filtering and transforming. It panics unless
+// 1 <= min <= max.
+func WithNGramRange(min, max int) StdOption {
+	if min < 1 || max < min {
+		panic(fmt.Sprintf("invalid n-gram range: %d-%d", min, max))
+	}
+
+	return func(t *StdTokenizer) {
+		t.ngramMin = min
+		t.ngramMax = max
+	}
+}
+
+// Filters overrides the list of predicates. This includes the stop-word
+// filter; use WithStopWords afterwards to combine both.
 func Filters(f ...Predicate) StdOption {
 	return func(t *StdTokenizer) {
 		t.filters = f
+		t.stopwords = nil
+	}
+}
+
+// WithStopWords replaces the built-in english stop words with the supplied
+// ones, which are dropped regardless of case before any other filter runs.
+// An empty list disables stop-word filtering.
+func WithStopWords(words []string) StdOption {
+	return func(t *StdTokenizer) {
+		t.stopwords = nil
+		if len(words) == 0 {
+			return
+		}
+		t.stopwords = stopwordSet(words)
+
+		sorted := make([]string, 0, len(t.stopwords))
+		for word := range t.stopwords {
+			sorted = append(sorted, word)
+		}
+		sort.Strings(sorted)
+		t.stopwordID = fmt.Sprintf("stopwords(%x)", sha256.Sum256([]byte(strings.Join(sorted, "\x00"))))
 	}
 }
+
+// stopwordSet case folds the words into a set
+func stopwordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[foldCase(word)] = true
+	}
+	return set
+}
+
+// foldCase applies Unicode full case folding, which unlike strings.ToLower
+// also maps e.g. "ß" to "ss" and the final sigma "ς" to "σ"
+func foldCase(s string) string {
+	return cases.Fold().String(s)
+}
@@ -78,3 +78,228 @@ func options(opts ...StdOption) []StdOption {
 func assertions(assertions ...assertion) []assertion {
 	return assertions
 }
+
+func TestTokenizeStats(t *testing.T) {
+	tokens, stats := NewTokenizer().TokenizeStats(toReader(text))
+	doTokenizeTest(t, tokens)
+
+	if stats.Read != 9 || stats.Dropped != 2 {
+		t.Errorf("Expected 9 read and 2 dropped tokens; actual: %d read, %d dropped", stats.Read, stats.Dropped)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	standard := NewTokenizer().Signature()
+	if standard != NewTokenizer(BufferSize(1)).Signature() {
+		t.Errorf("Expected the buffer size not to affect the signature")
+	}
+	if standard == NewTokenizer(Transforms(toUpper)).Signature() {
+		t.Errorf("Expected different transforms to change the signature")
+	}
+	if standard == NewTokenizer(Filters()).Signature() {
+		t.Errorf("Expected different filters to change the signature")
+	}
+	if standard != NewTokenizer(Filters(IsNotStopWord)).Signature() {
+		t.Errorf("Expected the built-in stop words to match the stop-word predicate")
+	}
+	if standard == NewTokenizer(WithStopWords([]string{"the"})).Signature() {
+		t.Errorf("Expected different stop words to change the signature")
+	}
+	if standard == NewTokenizer(Transforms(strings.ToLower)).Signature() {
+		t.Errorf("Expected lowercasing without case folding to change the signature")
+	}
+	if standard == NewTokenizer(WithStemmer("english")).Signature() {
+		t.Errorf("Expected a stemmer to change the signature")
+	}
+}
+
+func TestWithUnicodeNormalization(t *testing.T) {
+	composed, decomposed := "caf\u00e9", "cafe\u0301"
+	tests := []struct {
+		Name     string
+		Opts     []StdOption
+		Expected string
+	}{
+		{"Default", options(), decomposed},
+		{"Enabled", options(WithUnicodeNormalization(true)), composed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var actual []string
+			for token := range NewTokenizer(test.Opts...).Tokenize(toReader(decomposed)) {
+				actual = append(actual, token)
+			}
+			if len(actual) != 1 || actual[0] != test.Expected {
+				t.Errorf("Expected %q; actual: %q", test.Expected, actual)
+			}
+		})
+	}
+
+	if NewTokenizer().Signature() == NewTokenizer(WithUnicodeNormalization(true)).Signature() {
+		t.Errorf("Expected normalization to change the signature")
+	}
+}
+
+func TestWithLowercase(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Opts     []StdOption
+		Expected []string
+	}{
+		{"Default", options(), []string{"white", "école", "kitty", "strasse", "strasse"}},
+		{"Enabled", options(WithLowercase(true)), []string{"white", "école", "kitty", "strasse", "strasse"}},
+		{"Disabled", options(WithLowercase(false)), []string{"White", "ÉCOLE", "KITTY", "Straße", "STRASSE"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var actual []string
+			for token := range NewTokenizer(test.Opts...).Tokenize(toReader("The White ÉCOLE KITTY Straße STRASSE")) {
+				actual = append(actual, token)
+			}
+			if strings.Join(actual, " ") != strings.Join(test.Expected, " ") {
+				t.Errorf("Expected %v; actual: %v", test.Expected, actual)
+			}
+		})
+	}
+
+	if NewTokenizer().Signature() == NewTokenizer(WithLowercase(false)).Signature() {
+		t.Errorf("Expected disabling lowercasing to change the signature")
+	}
+}
+
+func TestWithStripPunctuation(t *testing.T) {
+	input := `"Black kitty." (white) e-mail, don't -- dog!?`
+	tests := []struct {
+		Name     string
+		Opts     []StdOption
+		Expected []string
+	}{
+		{"Default", options(), []string{`"black`, `kitty."`, "(white)", "e-mail,", "don't", "--", "dog!?"}},
+		{"Enabled", options(WithStripPunctuation(true)), []string{"black", "kitty", "white", "e-mail", "don't", "dog"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var actual []string
+			for token := range NewTokenizer(test.Opts...).Tokenize(toReader(input)) {
+				actual = append(actual, token)
+			}
+			if strings.Join(actual, " ") != strings.Join(test.Expected, " ") {
+				t.Errorf("Expected %v; actual: %v", test.Expected, actual)
+			}
+		})
+	}
+
+	if NewTokenizer().Signature() == NewTokenizer(WithStripPunctuation(true)).Signature() {
+		t.Errorf("Expected stripping punctuation to change the signature")
+	}
+}
+
+func TestWithMinTokenLength(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Opts     []StdOption
+		Expected []string
+	}{
+		{"Default", options(), []string{"x", "ok", "cat", "été"}},
+		{"Three", options(WithMinTokenLength(3)), []string{"cat", "été"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var actual []string
+			for token := range NewTokenizer(test.Opts...).Tokenize(toReader("x ok cat été")) {
+				actual = append(actual, token)
+			}
+			if strings.Join(actual, " ") != strings.Join(test.Expected, " ") {
+				t.Errorf("Expected %v; actual: %v", test.Expected, actual)
+			}
+		})
+	}
+
+	if NewTokenizer().Signature() != NewTokenizer(WithMinTokenLength(1)).Signature() {
+		t.Errorf("Expected the default minimum length not to affect the signature")
+	}
+	if NewTokenizer().Signature() == NewTokenizer(WithMinTokenLength(3)).Signature() {
+		t.Errorf("Expected a minimum length to change the signature")
+	}
+}
+
+func TestWithStemmer(t *testing.T) {
+	var actual []string
+	for token := range NewTokenizer(WithStemmer("en")).Tokenize(toReader("The Kittens were running and they jumped")) {
+		actual = append(actual, token)
+	}
+	if expected := []string{"kitten", "run", "jump"}; strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+
+	actual = nil
+	for token := range NewTokenizer(WithStemmer("es"), WithStopWords(nil)).Tokenize(toReader("Los gatitos corriendo")) {
+		actual = append(actual, token)
+	}
+	if expected := []string{"los", "gatit", "corr"}; strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v; actual: %v", expected, actual)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected an unsupported language to panic")
+		}
+	}()
+	WithStemmer("klingon")
+}
+
+func TestWithNGramRange(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Min, Max int
+		Expected []string
+	}{
+		{"Unigrams", 1, 1, []string{"white", "german", "shepherd"}},
+		{"Bigrams", 2, 2, []string{"white_german", "german_shepherd"}},
+		{"Mixed", 1, 2, []string{"white", "german", "white_german", "shepherd", "german_shepherd"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var actual []string
+			for token := range NewTokenizer(WithNGramRange(test.Min, test.Max)).Tokenize(toReader("The White German Shepherd")) {
+				actual = append(actual, token)
+			}
+			if strings.Join(actual, " ") != strings.Join(test.Expected, " ") {
+				t.Errorf("Expected %v; actual: %v", test.Expected, actual)
+			}
+		})
+	}
+
+	if NewTokenizer().Signature() == NewTokenizer(WithNGramRange(1, 2)).Signature() {
+		t.Errorf("Expected an n-gram range to change the signature")
+	}
+}
+
+func TestWithStopWords(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Opts     []StdOption
+		Expected []string
+	}{
+		{"Built-in", options(), []string{"quick", "brown", "fox", "jumped", "over", "lazy", "dog"}},
+		{"Custom", options(WithStopWords([]string{"QUICK", "Lazy"})), []string{"the", "brown", "fox", "jumped", "over", "the", "dog"}},
+		{"Disabled", options(WithStopWords(nil)), []string{"the", "quick", "brown", "fox", "jumped", "over", "the", "lazy", "dog"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var actual []string
+			for token := range NewTokenizer(test.Opts...).Tokenize(toReader(text)) {
+				actual = append(actual, token)
+			}
+			if strings.Join(actual, " ") != strings.Join(test.Expected, " ") {
+				t.Errorf("Expected %v; actual: %v", test.Expected, actual)
+			}
+		})
+	}
+}